@@ -1,11 +1,13 @@
 package bit
 
 import (
-	"bufio"
 	"errors"
 	"os"
 	"fmt"
-	"unicode"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
 	"github.com/jroimartin/gocui"
 )
 
@@ -18,12 +20,15 @@ const BitLeft = "◀"
 const BitRight = "▶"
 
 const HelpText = "n: next step    p: previous step    f: first step    l: last step    s: switch world    q: quit"
+const WorldsHelpText = "n: next step    p: previous step    f: first step    l: last step    s: switch world    w: next world    q: quit"
 
 
 
 
 /*
    Enumeration to represent the various colors of a square
+   Wildcard is only used in goal worlds for squares that can be any color.
+   Edge is returned by FrontColor, LeftColor and RightColor for squares outside of the world.
 */
 type Color int
 const (
@@ -32,19 +37,68 @@ const (
 	Red
 	Blue
 	Green
+	Wildcard
+	Edge
 )
+
+// The names of the colors, used by Color.String and ParseColor.
+var color_names = map[Color]string{
+	White:    "white",
+	Black:    "black",
+	Red:      "red",
+	Blue:     "blue",
+	Green:    "green",
+	Wildcard: "any",
+	Edge:     "edge",
+}
+
+/*
+   This function returns the name of a color, such as "red".
+*/
+func (color Color) String() string {
+	if name, ok := color_names[color]; ok {
+		return name
+	}
+	if custom, ok := lookup_custom_color(color); ok {
+		return custom.name
+	}
+	return fmt.Sprintf("Color(%d)", int(color))
+}
+
+/*
+   This function returns the color with the given name, such as "red".
+   Names are not case sensitive. It returns an error wrapping ErrInvalidColor if there is no such color.
+   Wildcard and Edge only mark squares of goal worlds and squares outside of the world,
+   so "any" and "edge" are not accepted.
+*/
+func ParseColor(name string) (Color, error) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for color, color_name := range color_names {
+		if color_name == lower && color != Wildcard && color != Edge {
+			return color, nil
+		}
+	}
+	palette_lock.RLock()
+	defer palette_lock.RUnlock()
+	for i, custom := range custom_colors {
+		if custom.name == lower {
+			return first_custom_color + Color(i), nil
+		}
+	}
+	return White, fmt.Errorf("%w: %q", ErrInvalidColor, name)
+}
 /*
    Deletes the view that shows the steps to replace it with a new one that has been made wide enough
    to fit the width of the current step.
 */
 func update_step_view() {
 	gui_i.DeleteView("Steps")
-	if step_v, err := gui_i.SetView("Steps", max_x/2-len(bit_state_actions[current_state]),max_y/2-12,max_x/2+len(bit_state_actions[current_state]), max_y/2-10); err != nil {
+	if step_v, err := gui_i.SetView("Steps", max_x/2-len(gui_session.actions[current_state]),max_y/2-12,max_x/2+len(gui_session.actions[current_state]), max_y/2-10); err != nil {
 		if err != gocui.ErrUnknownView {
 			fmt.Println(err.Error())
 			os.Exit(1)
 		}
-		fmt.Fprintln(step_v, current_state, ": ", bit_state_actions[current_state])
+		fmt.Fprintln(step_v, current_state, ": ", gui_session.actions[current_state])
 	}
 }
 /*
@@ -53,7 +107,7 @@ func update_step_view() {
 func first_state(gui *gocui.Gui, world *gocui.View) error {
 	world.Clear()
 	current_state = 0
-	print_world(world, bit_states[current_state].face, bit_states[current_state].world)
+	print_state(world)
 	update_step_view()
 	return nil
 }
@@ -62,10 +116,10 @@ func first_state(gui *gocui.Gui, world *gocui.View) error {
    Goes to the next step of the bit world.
 */
 func next_state(gui *gocui.Gui, world *gocui.View) error {
-	if current_state < len(bit_states) - 1 {
+	if current_state < len(gui_session.states) - 1 {
 		world.Clear()
 		current_state++
-		print_world(world, bit_states[current_state].face, bit_states[current_state].world)
+		print_state(world)
 		update_step_view()
 		return nil
 	}
@@ -77,8 +131,8 @@ func next_state(gui *gocui.Gui, world *gocui.View) error {
 */
 func last_state(gui *gocui.Gui, world *gocui.View) error {
 	world.Clear()
-	current_state = len(bit_states) - 1
-	print_world(world, bit_states[current_state].face, bit_states[current_state].world)
+	current_state = len(gui_session.states) - 1
+	print_state(world)
 	update_step_view()
 	return nil
 }
@@ -91,7 +145,7 @@ func prev_state(gui *gocui.Gui, world *gocui.View) error {
 	if current_state > 0 {
 		world.Clear()
 		current_state--
-		print_world(world, bit_states[current_state].face, bit_states[current_state].world)
+		print_state(world)
 		update_step_view()
 		return nil
 	}
@@ -110,9 +164,9 @@ func switch_world(gui *gocui.Gui, world *gocui.View) error {
 	world.Clear()
 	final_world = !final_world
 	if final_world {
-		print_world(world, bit_states[current_state].face, bit_states[current_state].final_world)
+		print_world(world, gui_session.states[current_state].final_world, gui_session.final_team, "")
 	} else {
-		print_world(world, bit_states[current_state].face, bit_states[current_state].world)
+		print_state(world)
 	}
 	return nil
 }
@@ -131,16 +185,54 @@ func reset_gui_globals() {
 	gui_i = nil
 	max_x = 0
 	max_y = 0
+	gui_sessions = nil
+	gui_session = nil
+	gui_results = false
+	current_trace = 0
+	current_state = 0
 }
+// Only one gui can run at a time, run_gui holds this lock while the gui is open.
+var gui_lock sync.Mutex
 // This is a pointer to the gui that is being used to display the bit world.
 var gui_i *gocui.Gui = nil
+// The sessions the gui can show, 'w' switches between them.
+var gui_sessions []*Session = nil
+// The session that the gui is showing.
+var gui_session *Session = nil
+// True when the gui shows the results of RunWorlds, so the title says if each world passed.
+var gui_results bool = false
+// The index of the session in gui_sessions that is being shown.
+var current_trace int = 0
+// The step of the session that the gui is showing.
+var current_state int = 0
 //This represents the width of the gui.
 var max_x int = 0
 //This represents the height of the gui.
 var max_y int = 0
 
 /*
-   This function draws the bit world in the gui and sets the keybindings for it.
+   Switches to the next session when the gui is showing several.
+*/
+func next_world(gui *gocui.Gui, world *gocui.View) error {
+	if len(gui_sessions) < 2 {
+		return nil
+	}
+	current_trace = (current_trace + 1) % len(gui_sessions)
+	gui_session = gui_sessions[current_trace]
+	final_world = false
+	gui.DeleteView("World")
+	gui.DeleteView("Header")
+	gui.DeleteView("Help")
+	if err := setup_views(gui); err != nil {
+		return err
+	}
+	update_step_view()
+	return nil
+}
+
+/*
+   This function draws the bit world in the gui.
+   When the gui is showing several sessions the title of the view says which one is shown.
 */
 func setup_world_view(gui *gocui.Gui, x0 int, y0 int, x1 int, y1 int) error {
 	
@@ -148,31 +240,19 @@ func setup_world_view(gui *gocui.Gui, x0 int, y0 int, x1 int, y1 int) error {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
-		current_state = len(bit_states) - 1
-
-		print_world(world, bit_states[current_state].face, bit_states[current_state].world)
+		current_state = len(gui_session.states) - 1
 
-		if err := gui.SetKeybinding("World", rune('n'), gocui.ModNone, next_state); err != nil {
-			return err
-		}
-		if err := gui.SetKeybinding("World", rune('p'), gocui.ModNone, prev_state); err != nil {
-			return err
-		}
-		if err := gui.SetKeybinding("World", rune('f'), gocui.ModNone, first_state); err != nil {
-			return err
-		}
-		if err := gui.SetKeybinding("World", rune('l'), gocui.ModNone, last_state); err != nil {
-			return err
-		}
-		if err := gui.SetKeybinding("", rune('q'), gocui.ModNone, quit); err != nil {
-			return err
-		}
-		if err := gui.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit); err != nil {
-			return err
-		}
-		if err := gui.SetKeybinding("World", rune('s'), gocui.ModNone, switch_world); err != nil {
-			return err
+		if gui_results {
+			var status string = "failed"
+			if gui_session.passed {
+				status = "passed"
+			}
+			world.Title = fmt.Sprintf("World %d of %d: %s", current_trace+1, len(gui_sessions), status)
+		} else if len(gui_sessions) > 1 {
+			world.Title = fmt.Sprintf("Run %d of %d", current_trace+1, len(gui_sessions))
 		}
+		print_state(world)
+
 		if _, err := gui.SetCurrentView("World"); err != nil {
 			return err
 		} 
@@ -180,6 +260,37 @@ func setup_world_view(gui *gocui.Gui, x0 int, y0 int, x1 int, y1 int) error {
 	return nil
 }
 
+/*
+   This function sets the keybindings of the bit gui.
+*/
+func setup_keybindings(gui *gocui.Gui) error {
+	if err := gui.SetKeybinding("World", rune('n'), gocui.ModNone, next_state); err != nil {
+		return err
+	}
+	if err := gui.SetKeybinding("World", rune('p'), gocui.ModNone, prev_state); err != nil {
+		return err
+	}
+	if err := gui.SetKeybinding("World", rune('f'), gocui.ModNone, first_state); err != nil {
+		return err
+	}
+	if err := gui.SetKeybinding("World", rune('l'), gocui.ModNone, last_state); err != nil {
+		return err
+	}
+	if err := gui.SetKeybinding("", rune('q'), gocui.ModNone, quit); err != nil {
+		return err
+	}
+	if err := gui.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit); err != nil {
+		return err
+	}
+	if err := gui.SetKeybinding("World", rune('s'), gocui.ModNone, switch_world); err != nil {
+		return err
+	}
+	if err := gui.SetKeybinding("World", rune('w'), gocui.ModNone, next_world); err != nil {
+		return err
+	}
+	return nil
+}
+
 /*
    This function initializes the step view for bit.
 */
@@ -188,7 +299,7 @@ func setup_step_view(gui *gocui.Gui, x0 int, y0 int, x1 int, y1 int) error {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
-		fmt.Fprintln(step_view, current_state, ": ", bit_state_actions[current_state])
+		fmt.Fprintln(step_view, current_state, ": ", gui_session.actions[current_state])
 	}
 	return nil
 }
@@ -196,22 +307,72 @@ func setup_step_view(gui *gocui.Gui, x0 int, y0 int, x1 int, y1 int) error {
 /*
    This function initializes the help view for the bit gui.
 */
-func setup_help_view(gui *gocui.Gui, x0 int, y0 int, x1 int, y1 int) error {
+func setup_help_view(gui *gocui.Gui, help_text string, x0 int, y0 int, x1 int, y1 int) error {
 	
 	if help_view, err := gui.SetView("Help", x0, y0, x1, y1); err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
-		fmt.Fprintln(help_view, HelpText)
+		fmt.Fprintln(help_view, help_text)
+	}
+	return nil
+}
+
+/*
+   This function initializes the header view that shows the title and description of the exercise.
+*/
+func setup_header_view(gui *gocui.Gui, x0 int, y0 int, x1 int, y1 int) error {
+	if header_view, err := gui.SetView("Header", x0, y0, x1, y1); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		header_view.Title = gui_session.exercise.Title
+		header_view.Wrap = true
+		fmt.Fprint(header_view, gui_session.exercise.Description)
 	}
 	return nil
 }
 
 /*
    This function launches the gui for bit.
-   
+   It shows the given sessions, or the session of the last bit that was made if none are given.
+   Press 'w' to switch between the sessions.
+   If another goroutine has a gui open this waits until it is closed.
 */
-func RunGui() {
+func RunGui(sessions ...*Session) {
+	if len(sessions) == 0 {
+		session_lock.Lock()
+		var last *Session = last_session
+		session_lock.Unlock()
+		if last == nil {
+			return
+		}
+		sessions = []*Session{last}
+	}
+	run_gui(sessions, false)
+}
+
+/*
+   This function launches the gui for the results of RunWorlds.
+   Press 'w' to switch between the timelines of the worlds.
+*/
+func RunGuiResults(results []*RunResult) {
+	if len(results) == 0 {
+		return
+	}
+	sessions := make([]*Session, len(results))
+	for i, result := range results {
+		sessions[i] = result.Session
+	}
+	run_gui(sessions, true)
+}
+
+/*
+   This function creates the gui, sets up the views and runs it until the user quits.
+*/
+func run_gui(sessions []*Session, results bool) {
+	gui_lock.Lock()
+	defer gui_lock.Unlock()
 	gui, err := gocui.NewGui(gocui.OutputNormal)
 	if err != nil {
 		fmt.Println(err.Error())
@@ -221,10 +382,43 @@ func RunGui() {
 	defer reset_gui_globals()
 	
 	gui_i = gui
+	gui_sessions = sessions
+	gui_session = sessions[0]
+	gui_results = results
 
 	max_x, max_y = gui.Size()
 
+	if err := setup_views(gui); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	if err := setup_step_view(gui, max_x/2-len(gui_session.actions[current_state]),max_y/2-12,max_x/2+len(gui_session.actions[current_state]), max_y/2-10); err != nil {
+		if err != gocui.ErrUnknownView {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if err := setup_keybindings(gui); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	if err := gui.MainLoop(); err != nil && err != gocui.ErrQuit {
+		os.Exit(1)
+	}
+
+}
+
+/*
+   This function sets up the views that depend on the size of the world and the exercise.
+   These are the world view, the header view and the help view.
+*/
+func setup_views(gui *gocui.Gui) error {
 	var x0, y0, x1, y1 int
+	var world_width int = gui_session.width
+	var world_height int = gui_session.height
 	
 	if (world_width * 5) % 2 != 0 {
 		x0 = max_x/2 - (world_width*5/2)
@@ -243,120 +437,207 @@ func RunGui() {
 		
 	
 	if err := setup_world_view(gui, x0, y0, x1, y1); err != nil {
-		if err != gocui.ErrUnknownView {
-			fmt.Println(err.Error())
-			os.Exit(1)
-		} else {
-			fmt.Println(err.Error())
-			os.Exit(1)
-		}
+		return err
 	}
 
-
-	if err := setup_step_view(gui, max_x/2-len(bit_state_actions[current_state]),max_y/2-12,max_x/2+len(bit_state_actions[current_state]), max_y/2-10); err != nil {
-		if err != gocui.ErrUnknownView {
-			fmt.Println(err.Error())
-			os.Exit(1)
+	if gui_session.exercise != nil {
+		lines := strings.Count(gui_session.exercise.Description, "\n") + 1
+		if err := setup_header_view(gui, 0, 0, max_x-1, lines+1); err != nil {
+			return err
 		}
 	}
 
-	if err := setup_help_view(gui, max_x/2 - len(HelpText)/2 -1, max_y/2+(world_height)+2, max_x/2+len(HelpText)/2 +1, max_y/2+(world_height) +4); err != nil {
-		if err != gocui.ErrUnknownView {
-			fmt.Println(err.Error())
-			os.Exit(1)
-		}
+	var help_text string = HelpText
+	if len(gui_sessions) > 1 {
+		help_text = WorldsHelpText
 	}
-
-	if err := gui.MainLoop(); err != nil && err != gocui.ErrQuit {
-		os.Exit(1)
+	if err := setup_help_view(gui, help_text, max_x/2 - len(help_text)/2 -1, max_y/2+(world_height)+2, max_x/2+len(help_text)/2 +1, max_y/2+(world_height) +4); err != nil {
+		return err
 	}
+	return nil
+}
 
+/*
+   This function initializes the timeline of a session with the state bit starts in.
+*/
+func init_bit_state(bit *Bit) {
+	session := bit.session
+	dest := copy_squares(bit.world)
+	session.states[0] = &Bit{ id: bit.id, face: bit.face, steps: bit.steps, x: bit.x, y: bit.y, world: dest, final_world: bit.final_world, final_face: bit.final_face, holding: bit.holding, team: session.snapshot_team()}
+	session.actions[0] = "initial state"
+	check_loop(bit)
 }
 
 /*
-   This function resets the global variables that bit and the gui use to keep track of the state.
+   This function is called whenever bit does an action.
+   This adds the action to the list of actions that bit has taken.
+   When several bits share the world the action starts with the id of the bit that did it.
 */
-func reset_bit_globals() {
-	bit_snapshots = make([]int, 0)
-	current_snapshot = 0
-	current_state = 0
-	bit_states = make([]*Bit, 1)
-	bit_state_actions = make([]string, 1)
-	error_occured = nil
-	world_width = 0
-	world_height = 0
+func add_bit_state(bit *Bit, action string) {
+	session := bit.session
+	dest := copy_squares(bit.world)
+	session.states = append(session.states, &Bit{ id: bit.id, face: bit.face, steps: bit.steps, x: bit.x, y: bit.y, world: dest, final_world: bit.final_world, final_face: bit.final_face, holding: bit.holding, team: session.snapshot_team()})
+	if len(session.team) > 1 {
+		action = bit.id + ": " + action
+	}
+	session.actions = append(session.actions, action)
+	check_loop(bit)
 }
 
-// Snapshots of the world at steps the user has created.
-var bit_snapshots []int = make([]int, 0)
-var bit_snapshot_names []string = make([]string, 0)
+/*
+   This function stops bit when loop detection is on and bit has come back to the same state too many times.
+   Steps that don't change the state, such as IsRed, are skipped so that checking a square over and over
+   isn't mistaken for a loop.
+*/
+func check_loop(bit *Bit) {
+	session := bit.session
+	if session.loop_repeats <= 0 || session.err != nil {
+		return
+	}
+	hash := session.hash_state(bit.world)
+	if hash == session.last_state_hash {
+		return
+	}
+	session.last_state_hash = hash
+	var step int = len(session.states) - 1
+	session.seen_states[hash] = append(session.seen_states[hash], step)
+	if steps := session.seen_states[hash]; len(steps) > session.loop_repeats {
+		stop_with_error(action_error(ErrInfiniteLoop, fmt.Sprintf("Infinite loop: steps %d to %d repeat without changing the world", steps[len(steps)-2], step)), bit)
+	}
+}
+
+/*
+   This function is called at the start of every action.
+   It returns false if bit must skip the action, either because an error has occured or because
+   the exercise doesn't allow the action, the step limit has been reached or the run was cancelled,
+   in which case bit is stopped with an error.
+   An action taken after bit has stopped ends a program run by Run or RunWorlds, see halt_program.
+   The steps GoTo takes are not checked against the actions the exercise allows.
+*/
+func (bit *Bit) start_action(action string) bool {
+	session := bit.session
+	if session.err != nil {
+		halt_program(session)
+		return false
+	}
+	if session.ctx != nil && session.ctx.Err() != nil {
+		stop_with_error(fmt.Errorf("Cancelled: %w", session.ctx.Err()), bit)
+		return false
+	}
+	if session.step_limit > 0 && len(session.states)-1 >= session.step_limit {
+		stop_with_error(action_error(ErrStepLimit, "Step limit exceeded"), bit)
+		return false
+	}
+	if session.allowed_actions != nil && !session.allowed_actions[action] && !session.navigating {
+		stop_with_error(action_error(ErrNotAllowed, "Action not allowed: " + action), bit)
+		return false
+	}
+	return true
+}
 
-// The current snapshot of the world.
-var current_snapshot int = 0
+/*
+   This function sets the most steps the session of bit may take, 0 means there is no limit.
+   Every action bit takes is a step, including checks such as IsRed, so a loop that never ends is stopped
+   with a "Step limit exceeded" error and every action after it does nothing.
+   When the program runs with Run or RunWorlds the next action after bit stops also ends the program,
+   so a loop such as 'for !bit.IsBlue() { bit.Left() }' doesn't keep going.
+*/
+func (bit *Bit) SetStepLimit(limit int) {
+	bit.session.step_limit = limit
+}
 
-// The current state of the world, used by the gui.
-var current_state int = 0
-// The states of the world at each step that bit has taken.
-var bit_states []*Bit = make([]*Bit, 1)
-// A list that is the action that bit made at a step
-var bit_state_actions []string = make([]string, 1)
+/*
+   This function sets the step limit that every session made after it starts with, see SetStepLimit.
+   An exercise with a step budget uses its budget instead.
+*/
+func SetDefaultStepLimit(limit int) {
+	session_lock.Lock()
+	defer session_lock.Unlock()
+	default_step_limit = limit
+}
 
-// A global that specifies that an error has occured so that we can skip every other bit step.
-var error_occured error = nil
+// The step limit that every new session starts with.
+var default_step_limit int = 0
 
-// The width of the world.
-var world_width int = 0
-// The height of the world.
-var world_height int = 0
+/*
+   This function turns on loop detection for the session of bit, 0 turns it off.
+   After every step that changes the position or direction of a bit or the world, the state is compared
+   with the states before it. If the same state is reached more than repeats times bit is stopped with an error
+   that names the steps that repeated, and the actions after it do nothing like they do after SetStepLimit.
+   A program may come back to the same state on purpose, so repeats should be at least 2.
+*/
+func (bit *Bit) SetLoopDetection(repeats int) {
+	bit.session.loop_repeats = repeats
+	bit.session.seen_states = make(map[uint64][]int)
+	bit.session.last_state_hash = 0
+	check_loop(bit)
+}
 
 /*
-   This function initializes the bit globals.
+   This function sets the loop detection that every session made after it starts with, see SetLoopDetection.
 */
-func init_bit_state(bit *Bit) {
-	dest := make([][]Square, len(bit.world))
-	for i := range dest {
-		dest[i] = make([]Square, len(bit.world[i]))
-		copy(dest[i], bit.world[i])
-	}
-	bit_states[0] = &Bit{ face: bit.face, steps: bit.steps, x: bit.x, y: bit.y, world: dest, final_world: bit.final_world}
-	bit_state_actions[0] = "initial state"
+func SetDefaultLoopDetection(repeats int) {
+	session_lock.Lock()
+	defer session_lock.Unlock()
+	default_loop_repeats = repeats
 }
 
+// The loop detection that every new session starts with.
+var default_loop_repeats int = 0
+
 /*
-   This function is called whenever bit does an action.
-   This adds the action to the list of actions that bit has taken.
+   This function stops a program run by run_program when it takes an action after bit has stopped,
+   so a loop that never ends can't keep a run going. It panics and run_program recovers.
+   Outside of run_program it does nothing and the action is skipped, the program is never exited.
 */
-func add_bit_state(bit *Bit, action string) {
-	dest := make([][]Square, len(bit.world))
-	for i := range dest {
-		dest[i] = make([]Square, len(bit.world[i]))
-		copy(dest[i], bit.world[i])
+func halt_program(session *Session) {
+	if session.running {
+		panic(halt_signal{})
 	}
-	bit_states = append(bit_states, &Bit{ face: bit.face, steps: bit.steps, x: bit.x, y: bit.y, world: dest, final_world: bit.final_world})
-	bit_state_actions = append(bit_state_actions, action)
 }
 
+/*
+   This struct is the value halt_program panics with to stop a program run by run_program.
+*/
+type halt_signal struct{}
+
 /*
    This function is called whenever bit makes an invalid move.
    This function adds the error to the list of actions that bit has taken.
 */
 func stop_display_error(msg string, bit *Bit) {
-	error_occured = errors.New(msg)
-	add_bit_state(bit, msg)
+	stop_with_error(errors.New(msg), bit)
+}
+
+/*
+   This function stops bit with an error and adds the error to the list of actions that bit has taken.
+   During a Try function an error that bit can recover from is added to the list but doesn't stop bit,
+   the Try function returns it instead.
+*/
+func stop_with_error(err error, bit *Bit) {
+	session := bit.session
+	if session.trying && is_recoverable(err) {
+		session.try_err = err
+		add_bit_state(bit, err.Error())
+		return
+	}
+	session.err = err
+	add_bit_state(bit, err.Error())
 }
 
 /*
    This Struct represents a square in the world.
-   It has a color and a boolean that represents whether or not it has a bit.
+   It has a color, a boolean that represents whether or not it has a bit and the number of objects on it.
 */
 type Square struct {
 	color Color
 	has_bit bool
+	objects int
 }
 
 /*
    This function loads the world from a file.
-   It returns a 2d array of squares.
+   It returns the parsed world or an error that names the file and the line and column of the problem.
    The format of the file is as follows:
    Use 'u' 'd' 'l' 'r' to represent the direction that the bit will start facing.
    then use '-' to represent a white square, 'r' to represent a red square, 'b' to represent a blue square, 'g' to represent a green square, and 'x' to represent a black square.
@@ -370,51 +651,38 @@ Example:
    0 0
    
 */
-func load_world(file_name string) [][]Square {
+func load_world(file_name string) (*World, error) {
 	file, err := os.Open(file_name)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		return nil, err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	var world [][]Square
-	var start_x int = -1
-	var start_y int = -1
-	for scanner.Scan() {
-		var row []Square
-		var broken bool = false
-		for _, char := range scanner.Text() {
-			if char == '-' {
-				row = append(row, Square{color: White, has_bit: false})
-			} else if char == 'r' {
-				row = append(row, Square{color: Red, has_bit: false})
-			} else if char == 'b' {
-				row = append(row, Square{color: Blue, has_bit: false})
-			} else if char == 'g' {
-				row = append(row, Square{color: Green, has_bit: false})
-			} else if char == 'x' {
-				row = append(row, Square{color: Black, has_bit: false})
-			} else if char == 'u' || char == 'd' || char == 'l' || char == 'r' {
-				broken = true
-				break
-			} else if unicode.IsDigit(rune(char)) {
-				if start_x == -1 {
-					start_x = int(char) - 48
-				} else {
-					start_y = int(char) - 48
-				}
-			}
-		}
-		if !broken {
-			world = append(world, row)
-		}
+	return read_world(file, file_name)
+}
+
+/*
+   This function loads the world from a file in a file system such as an embed.FS.
+*/
+func load_world_fs(fsys fs.FS, file_name string) (*World, error) {
+	file, err := fsys.Open(file_name)
+	if err != nil {
+		return nil, err
 	}
-	world[start_y][start_x].has_bit = true
-	world_height = len(world)
-	world_width = len(world[0])
-	return world
+	defer file.Close()
+
+	return read_world(file, file_name)
+}
+
+/*
+   Helper function that parses a world and adds the file name to any error.
+*/
+func read_world(reader io.Reader, file_name string) (*World, error) {
+	world, err := ParseWorld(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file_name, err)
+	}
+	return world, nil
 }
 
 
@@ -423,41 +691,192 @@ func load_world(file_name string) [][]Square {
    The starting world is the world that bit starts in.
    The ending world is the world that bit must reach.
    This function should be called to get a bit.
+   If either world can't be loaded the error is printed and the program exits.
+   Use LoadBit to handle the error instead.
 */
 func GetBit(start_world string, end_world string) *Bit {
-	reset_bit_globals()
-	file, err := os.Open(start_world)
+	bit, err := LoadBit(start_world, end_world)
 	if err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
 	}
-	var bit_direction string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		for _, char := range scanner.Text() {
-			if char == 'u' {
-				bit_direction = BitUp
-			} else if char == 'd' {
-				bit_direction = BitDown
-			} else if char == 'l' {
-				bit_direction = BitLeft
-			} else if char == 'r' {
-				bit_direction = BitRight
-			} else {
-				break
-			}
-		}
+	return bit
+}
+
+/*
+   This function returns a bit and takes in the starting world and the ending world.
+   It works like GetBit but returns an error if either world can't be loaded
+   or if the worlds don't go together, see ValidateWorlds.
+*/
+func LoadBit(start_world string, end_world string) (*Bit, error) {
+	initial_world, err := load_world(start_world)
+	if err != nil {
+		return nil, err
+	}
+	final_world, err := load_world(end_world)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateWorlds(initial_world, final_world); err != nil {
+		return nil, fmt.Errorf("%s and %s: %w", start_world, end_world, err)
 	}
-	file.Close()
+	return setup_bits(initial_world, final_world, false)[0], nil
+}
 
-	var initial_world [][]Square = load_world(start_world)
-	var final_world [][]Square = load_world(end_world)
+/*
+   This function returns a bit for a start world and a goal world that are already loaded,
+   for example ones made by ParseWorld or GenerateWorld.
+   It returns an error if the worlds don't go together, see ValidateWorlds.
+*/
+func NewBit(start_world *World, end_world *World) (*Bit, error) {
+	if err := ValidateWorlds(start_world, end_world); err != nil {
+		return nil, err
+	}
+	return setup_bits(start_world, end_world, false)[0], nil
+}
+
+/*
+   This function works like GetBit but loads the worlds from a file system.
+   This allows the worlds to be embedded in the program with go:embed.
+
+Example:
+   //go:embed worlds
+   var worlds embed.FS
 
-	bit := new_bit(bit_direction, initial_world, final_world)
-	init_bit_state(bit)
+   bit := GetBitFS(worlds, "worlds/start.txt", "worlds/end.txt")
+*/
+func GetBitFS(fsys fs.FS, start_world string, end_world string) *Bit {
+	bit, err := LoadBitFS(fsys, start_world, end_world)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
 	return bit
 }
 
+/*
+   This function works like LoadBit but loads the worlds from a file system.
+*/
+func LoadBitFS(fsys fs.FS, start_world string, end_world string) (*Bit, error) {
+	initial_world, err := load_world_fs(fsys, start_world)
+	if err != nil {
+		return nil, err
+	}
+	final_world, err := load_world_fs(fsys, end_world)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateWorlds(initial_world, final_world); err != nil {
+		return nil, fmt.Errorf("%s and %s: %w", start_world, end_world, err)
+	}
+	return setup_bits(initial_world, final_world, false)[0], nil
+}
+
+/*
+   This function returns one bit for every bit of the starting world, in the order they are listed.
+   All of the bits share the world and the steps, so the gui shows what every bit did in the order they did it.
+   A bit can't move onto a square that has another bit on it.
+   If either world can't be loaded the error is printed and the program exits.
+   Use LoadBits to handle the error instead.
+
+Example:
+   r
+   -----
+   -----
+   bit: alice 0 0
+   bit: bob 0 1
+*/
+func GetBits(start_world string, end_world string) []*Bit {
+	bits, err := LoadBits(start_world, end_world)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	return bits
+}
+
+/*
+   This function works like GetBits but returns an error if either world can't be loaded
+   or if the worlds don't go together, see ValidateWorlds.
+*/
+func LoadBits(start_world string, end_world string) ([]*Bit, error) {
+	initial_world, err := load_world(start_world)
+	if err != nil {
+		return nil, err
+	}
+	final_world, err := load_world(end_world)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateWorlds(initial_world, final_world); err != nil {
+		return nil, fmt.Errorf("%s and %s: %w", start_world, end_world, err)
+	}
+	return setup_bits(initial_world, final_world, false), nil
+}
+
+/*
+   This function works like NewBit but returns one bit for every bit of the start world.
+*/
+func NewBits(start_world *World, end_world *World) ([]*Bit, error) {
+	if err := ValidateWorlds(start_world, end_world); err != nil {
+		return nil, err
+	}
+	return setup_bits(start_world, end_world, false), nil
+}
+
+/*
+   This function makes a new session and creates a bit for every bit of the start world.
+   The squares of the worlds are copied so that the worlds can be used again, the bits share the copy.
+   If goal_faces is true each bit must face the direction it has in the goal world at the end,
+   otherwise any direction is fine.
+*/
+func setup_bits(initial_world *World, final_world *World, goal_faces bool) []*Bit {
+	session := new_session()
+	session.height = len(initial_world.squares)
+	session.width = len(initial_world.squares[0])
+
+	squares := copy_squares(initial_world.squares)
+	final_squares := copy_squares(final_world.squares)
+	session.legend = map[Color]custom_color{}
+	for _, legend := range []map[Color]custom_color{final_world.legend, initial_world.legend} {
+		for color, custom := range legend {
+			session.legend[color] = custom
+		}
+	}
+	for _, member := range final_world.team() {
+		if !goal_faces {
+			member.face = ""
+		}
+		session.final_team = append(session.final_team, member)
+	}
+	for _, member := range initial_world.team() {
+		bit := new_bit(member.face, squares, final_squares)
+		bit.session = session
+		bit.id = member.id
+		bit.x = member.x
+		bit.y = member.y
+		bit.final_face = session.final_face_of(member.id)
+		session.team = append(session.team, bit)
+	}
+	init_bit_state(session.team[0])
+	session_lock.Lock()
+	last_session = session
+	session_lock.Unlock()
+	return session.team
+}
+
+/*
+   Helper function that makes a deep copy of the squares of a world.
+*/
+func copy_squares(world [][]Square) [][]Square {
+	dest := make([][]Square, len(world))
+	for i := range dest {
+		dest[i] = make([]Square, len(world[i]))
+		copy(dest[i], world[i])
+	}
+	return dest
+}
+
 /*
    Helper function for locating bit for the initial world.
 */
@@ -494,14 +913,31 @@ func find_bit_y(world [][]Square) int {
    It has an x and y coordinate that represents its position in the world.
    It has a world that represents the world that it is currently in.
    It has a final_world that represents the world that it must reach.
+   It has a final_face that represents the direction it must be facing at the end, empty if any direction is fine.
+   It has an id that tells it apart from the other bits that share the world, empty if it is alone.
+   It has a number of objects that it is holding.
+   It has the session that keeps its steps, which it shares with the other bits in the world.
+   The states kept for the gui also have the team, the positions and directions of every bit at that step.
 */
 type Bit struct {
+	id string
 	face string
 	steps int
 	x int
 	y int
 	world [][]Square
 	final_world [][]Square
+	final_face string
+	holding int
+	session *Session
+	team []world_bit
+}
+
+/*
+   This function returns the id of bit from the world file, or an empty string if bit has none.
+*/
+func (bit *Bit) ID() string {
+	return bit.id
 }
 
 /*
@@ -520,28 +956,47 @@ func new_bit(direction string, world [][]Square, final_world[][]Square) *Bit {
 }
 
 
+/*
+   This function prints the state of the world at the current step to the screen.
+*/
+func print_state(v *gocui.View) {
+	state := gui_session.states[current_state]
+	print_world(v, state.world, state.team, state.id)
+}
+
 /*
    This function prints a bit to the screen.
-   It takes in a view and a bit face and a world.
+   It takes in a view, a world, the bits in the world and the id of the bit that acted.
+   The bit that acted is drawn in cyan and the others in black, when there are several bits
+   their ids are drawn above them. A bit without a direction is drawn as '?'.
+   The number of objects on a square is drawn at the bottom of the square.
 */ 
-func print_world(v *gocui.View, face string, world [][]Square) {
-	for _, row := range world {
+func print_world(v *gocui.View, world [][]Square, team []world_bit, actor string) {
+	legend := gui_session.legend
+	for y, row := range world {
 			for i := 0; i < 3; i++ {
-				for _, square := range row {
+				for x, square := range row {
+					var member world_bit
+					for _, other := range team {
+						if other.x == x && other.y == y {
+							member = other
+						}
+					}
+					var face string = member.face
+					if face == "" {
+						face = "?"
+					}
+					var foreground int = 30
+					if member.id == actor {
+						foreground = 36
+					}
 				
 					if square.has_bit && i == 1 {
-						switch square.color {
-						case White:
-							fmt.Fprint(v, "\x1b[36;47m  ",face, "  ")
-						case Red:
-							fmt.Fprint(v, "\x1b[36;41m  ",face, "  ")
-						case Blue:
-							fmt.Fprint(v, "\x1b[36;44m  ",face, "  ")
-						case Green:
-							fmt.Fprint(v, "\x1b[36;42m  ",face, "  ")
-						default: 
-							fmt.Fprint(v, "\x1b[36;47m  ",face, "  ")
-						}
+						fmt.Fprint(v, "\x1b[", foreground, ";", square_display(square.color, legend), "m  ", face, "  ")
+					} else if square.has_bit && i == 0 && len(team) > 1 {
+						fmt.Fprint(v, "\x1b[", foreground, ";", square_display(square.color, legend), "m", center_label(member.id))
+					} else if square.objects > 0 && i == 2 {
+						fmt.Fprint(v, "\x1b[30;", square_display(square.color, legend), "m", center_label(fmt.Sprint("●", square.objects)))
 					} else {
 						switch square.color {
 						case White:
@@ -554,8 +1009,14 @@ func print_world(v *gocui.View, face string, world [][]Square) {
 							fmt.Fprint(v, "\x1b[36;44m     ")
 						case Green:
 							fmt.Fprint(v, "\x1b[36;42m     ")
+						case Wildcard:
+							if i == 1 {
+								fmt.Fprint(v, "\x1b[30;47m  ?  ")
+							} else {
+								fmt.Fprint(v, "\x1b[30;47m ? ? ")
+							}
 						default: 
-							fmt.Fprint(v, "\x1b[36;47m     ")
+							fmt.Fprint(v, "\x1b[36;", custom_display(square.color, legend), "m     ")
 						}
 					}
 				}
@@ -564,12 +1025,43 @@ func print_world(v *gocui.View, face string, world [][]Square) {
 	}
 }
 
+/*
+   Helper function that centers a label in the width of a square, cutting it if it is too long.
+*/
+func center_label(label string) string {
+	runes := []rune(label)
+	if len(runes) > 5 {
+		runes = runes[:5]
+	}
+	return fmt.Sprintf("%-5s", strings.Repeat(" ", (5-len(runes))/2)+string(runes))
+}
+
+/*
+   Helper function that returns the ANSI background code used to draw a color.
+   Colors added by legends are drawn the way the legend of the world says.
+*/
+func square_display(color Color, legend map[Color]custom_color) int {
+	switch color {
+	case White:
+		return 47
+	case Black:
+		return 40
+	case Red:
+		return 41
+	case Blue:
+		return 44
+	case Green:
+		return 42
+	}
+	return custom_display(color, legend)
+}
+
 /*
    This function moves bit in the direction that it is facing.
-   It will mark an error if bit tries to move out of bounds or onto a black square.
+   It will mark an error if bit tries to move out of bounds, onto a black square or onto another bit.
 */
 func (bit *Bit) Move() {
-	if error_occured != nil {
+	if !bit.start_action("move") {
 		return
 	}
 	bit.steps++
@@ -585,7 +1077,7 @@ func (bit *Bit) Move() {
 		result = bit.moveRight()
 	}
 	if result != nil {
-		stop_display_error(result.Error(),bit)
+		stop_with_error(result, bit)
 		return
 	}
 	add_bit_state(bit, "move     ")
@@ -597,14 +1089,16 @@ func (bit *Bit) Move() {
 */
 func (b *Bit) moveUp() error {
 	if b.y -1 < 0 {
-		return errors.New("Out of bounds")
-	} else if b.world[b.y-1][b.x].color == White || b.world[b.y-1][b.x].color == Red || b.world[b.y-1][b.x].color == Blue || b.world[b.y-1][b.x].color == Green {
+		return action_error(ErrOutOfBounds, "Out of bounds")
+	} else if b.world[b.y-1][b.x].has_bit {
+		return action_error(ErrBlocked, "Blocked by another bit")
+	} else if b.world[b.y-1][b.x].color != Black {
 		b.world[b.y][b.x].has_bit = false
 		b.world[b.y-1][b.x].has_bit = true
 		b.y--
 		return nil
 	} else if b.world[b.y-1][b.x].color == Black {
-		return errors.New("Blocked")
+		return action_error(ErrBlocked, "Blocked")
 	}
 	return nil
 }
@@ -614,15 +1108,17 @@ func (b *Bit) moveUp() error {
    Returns an error if bit tries to make an invalid move.
 */
 func (b *Bit) moveDown() error {
-	if b.y +1 > len(b.world) {
-		return errors.New("Out of bounds")
-	} else if b.world[b.y+1][b.x].color == White || b.world[b.y+1][b.x].color == Red || b.world[b.y+1][b.x].color == Blue || b.world[b.y+1][b.x].color == Green {
+	if b.y +1 > len(b.world) -1 {
+		return action_error(ErrOutOfBounds, "Out of bounds")
+	} else if b.world[b.y+1][b.x].has_bit {
+		return action_error(ErrBlocked, "Blocked by another bit")
+	} else if b.world[b.y+1][b.x].color != Black {
 		b.world[b.y][b.x].has_bit = false
 		b.world[b.y+1][b.x].has_bit = true
 		b.y++
 		return nil
 	} else if b.world[b.y+1][b.x].color == Black {
-		return errors.New("Blocked")
+		return action_error(ErrBlocked, "Blocked")
 	}
 	return nil
 }
@@ -633,14 +1129,16 @@ func (b *Bit) moveDown() error {
 */
 func (b *Bit) moveLeft() error {
 	if b.x -1 < 0 {
-		return errors.New("Out of bounds")
-	} else if b.world[b.y][b.x-1].color == White || b.world[b.y][b.x-1].color == Red || b.world[b.y][b.x-1].color == Blue || b.world[b.y][b.x-1].color == Green {
+		return action_error(ErrOutOfBounds, "Out of bounds")
+	} else if b.world[b.y][b.x-1].has_bit {
+		return action_error(ErrBlocked, "Blocked by another bit")
+	} else if b.world[b.y][b.x-1].color != Black {
 		b.world[b.y][b.x].has_bit = false
 		b.world[b.y][b.x-1].has_bit = true
 		b.x--
 		return nil
 	} else if b.world[b.y][b.x-1].color == Black {
-		return errors.New("Blocked")
+		return action_error(ErrBlocked, "Blocked")
 	}
 	return nil
 }
@@ -651,14 +1149,16 @@ func (b *Bit) moveLeft() error {
 */
 func (b *Bit) moveRight() error {
 	if b.x +1 > len(b.world[0]) -1 {
-		return errors.New("Out of bounds")
-	} else if b.world[b.y][b.x+1].color == White || b.world[b.y][b.x+1].color == Red || b.world[b.y][b.x+1].color == Blue || b.world[b.y][b.x+1].color == Green {
+		return action_error(ErrOutOfBounds, "Out of bounds")
+	} else if b.world[b.y][b.x+1].has_bit {
+		return action_error(ErrBlocked, "Blocked by another bit")
+	} else if b.world[b.y][b.x+1].color != Black {
 		b.world[b.y][b.x].has_bit = false
 		b.world[b.y][b.x+1].has_bit = true
 		b.x++
 		return nil
 	} else if b.world[b.y][b.x+1].color == Black {
-		return errors.New("Blocked")
+		return action_error(ErrBlocked, "Blocked")
 	}
 	return nil
 }
@@ -667,7 +1167,7 @@ func (b *Bit) moveRight() error {
    This function makes bit turn left without moving.
 */
 func (b *Bit) Left() {
-	if error_occured != nil {
+	if !b.start_action("left") {
 		return
 	}
 	b.steps++
@@ -691,7 +1191,7 @@ func (b *Bit) Left() {
    This function makes bit turn right without moving.
 */
 func (b *Bit) Right() {
-	if error_occured != nil {
+	if !b.start_action("right") {
 		return
 	}
 	b.steps++
@@ -710,69 +1210,132 @@ func (b *Bit) Right() {
 
 /*
    This function causes bit to paint a color on the current square with a given color.
-   Valid colors are "red", "blue", and "green".
+   Valid colors are "red", "blue", "green" and the names of colors added with DefineColor or a legend.
+   The color is parsed with ParseColor and then painted with PaintColor.
 */
 func (bit *Bit) Paint(color string) {
-	if error_occured != nil {
+	parsed, err := ParseColor(color)
+	if err != nil {
+		if bit.start_action("paint") {
+			stop_with_error(action_error(ErrInvalidColor, "Invalid color: " + color), bit)
+		}
 		return
 	}
-	var success bool = false
-	if color == "red" {
-		bit.world[bit.y][bit.x].color = Red
-		success = true
-	}
-	if color == "blue" {
-		bit.world[bit.y][bit.x].color = Blue
-		success = true
-	}
-	if color == "green" {
-		bit.world[bit.y][bit.x].color = Green
-		success = true
-	} else {
-		stop_display_error("Invalid color: " + color,bit)
+	bit.PaintColor(parsed)
+}
+
+/*
+   This function causes bit to paint a color on the current square with a given color.
+   Valid colors are Red, Blue, Green and the colors added with DefineColor or a legend.
+*/
+func (bit *Bit) PaintColor(color Color) {
+	if !bit.start_action("paint") {
+		return
 	}
-	if success {
-		add_bit_state(bit, "paint " + color)
+	if _, custom := lookup_custom_color(color); color != Red && color != Blue && color != Green && !custom {
+		stop_with_error(action_error(ErrInvalidColor, "Invalid color: " + color.String()), bit)
+		return
 	}
+	bit.world[bit.y][bit.x].color = color
+	add_bit_state(bit, "paint " + color.String())
 }
 
 /*
    This function causes bit to erase the color on the current square.
 */
 func (bit *Bit) Erase() {
-	if error_occured != nil {
+	if !bit.start_action("erase") {
 		return
 	}
 	bit.world[bit.y][bit.x].color = White
 	add_bit_state(bit, "erase")
 }
 
+/*
+   This function makes bit pick up one of the objects on the current square.
+   It will mark an error if there are no objects on the square.
+*/
+func (bit *Bit) PickUp() {
+	if !bit.start_action("pickup") {
+		return
+	}
+	if bit.world[bit.y][bit.x].objects == 0 {
+		stop_with_error(action_error(ErrNoObject, "No object to pick up"), bit)
+		return
+	}
+	bit.world[bit.y][bit.x].objects--
+	bit.holding++
+	add_bit_state(bit, "pick up")
+}
+
+/*
+   This function makes bit drop one of the objects it is holding on the current square.
+   It will mark an error if bit isn't holding an object.
+*/
+func (bit *Bit) Drop() {
+	if !bit.start_action("drop") {
+		return
+	}
+	if bit.holding == 0 {
+		stop_with_error(action_error(ErrNotHolding, "Not holding an object"), bit)
+		return
+	}
+	bit.holding--
+	bit.world[bit.y][bit.x].objects++
+	add_bit_state(bit, "drop")
+}
+
+/*
+   This function checks if bit is holding at least one object.
+*/
+func (bit *Bit) IsHoldingObject() bool {
+	if !bit.start_action("isholdingobject") {
+		return false
+	}
+	add_bit_state(bit, "is holding object")
+	return bit.holding > 0
+}
+
+/*
+   This function returns the number of objects on the current square.
+*/
+func (bit *Bit) ObjectsHere() int {
+	if !bit.start_action("objectshere") {
+		return 0
+	}
+	add_bit_state(bit, "objects here")
+	return bit.world[bit.y][bit.x].objects
+}
+
 /*
    This function returns the color of the current square.
-   Valid colors are "red", "blue", "green", and "white".
+   It is one of "white", "red", "blue" and "green" or the name of a color added by a legend.
 */
 func (bit *Bit) GetColor() string {
-	if error_occured != nil {
+	if !bit.start_action("getcolor") {
 		return ""
 	}
 	add_bit_state(bit, "get color       ")
-	switch bit.world[bit.y][bit.x].color {
-	case Red:
-		return "red"
-	case Blue:
-		return "blue"
-	case Green:
-		return "green"
-	default:
-		return "white"
+	return bit.world[bit.y][bit.x].color.String()
+}
+
+/*
+   This function returns the color of the current square as a Color.
+   It is the same as GetColor, it returns White if bit has stopped because of an error.
+*/
+func (bit *Bit) ColorHere() Color {
+	if !bit.start_action("getcolor") {
+		return White
 	}
+	add_bit_state(bit, "get color       ")
+	return bit.world[bit.y][bit.x].color
 }
 
 /*
    This function checks if the the current square is red.
 */
 func (bit *Bit) IsRed() bool {
-	if error_occured != nil {
+	if !bit.start_action("isred") {
 		return false
 	}
 	add_bit_state(bit, "is red       ")
@@ -783,7 +1346,7 @@ func (bit *Bit) IsRed() bool {
    This function checks if the the current square is blue.
 */
 func (bit *Bit) IsBlue() bool {
-	if error_occured != nil {
+	if !bit.start_action("isblue") {
 		return false
 	}
 	add_bit_state(bit, "is blue       ")
@@ -794,113 +1357,333 @@ func (bit *Bit) IsBlue() bool {
    This function checks if the the current square is green.
 */
 func (bit *Bit) IsGreen() bool {
-	if error_occured != nil {
+	if !bit.start_action("isgreen") {
 		return false
 	}
 	add_bit_state(bit, "is green       ")
 	return bit.world[bit.y][bit.x].color == Green
 }
 
+/*
+   Helper function that checks if a square is within the bounds of the world, is not black
+   and doesn't have another bit on it.
+*/
+func (bit *Bit) is_clear(x int, y int) bool {
+	if y < 0 || y >= len(bit.world) || x < 0 || x >= len(bit.world[y]) {
+		return false
+	}
+	return bit.world[y][x].color != Black && !bit.world[y][x].has_bit
+}
+
 /*
    This function checks if the square infront of bit is clear.
-   Meaning that it is not black and is within the bounds of the world.
+   Meaning that it is not black, is within the bounds of the world and has no other bit on it.
 */
 func (bit *Bit) IsFrontClear() bool {
-	if error_occured != nil {
+	if !bit.start_action("isfrontclear") {
 		return false
 	}
 	add_bit_state(bit, "is front clear")
 	switch bit.face {
 	case BitUp:
-		return bit.world[bit.y-1][bit.x].color != Black || bit.y-1 < 0 
+		return bit.is_clear(bit.x, bit.y-1)
 	case BitDown:
-		return bit.world[bit.y+1][bit.x].color != Black || bit.y+1 >= len(bit.world)
+		return bit.is_clear(bit.x, bit.y+1)
 	case BitLeft:
-		return bit.world[bit.y][bit.x-1].color != Black || bit.x-1 < 0
+		return bit.is_clear(bit.x-1, bit.y)
 	case BitRight:
-		return bit.world[bit.y][bit.x+1].color != Black || bit.x+1 >= len(bit.world)
+		return bit.is_clear(bit.x+1, bit.y)
 	}
 	return false
 }
 
 /*
    This function checks if the square to the right of bit is clear.
-   Meaning that it is not black and is within the bounds of the world.
+   Meaning that it is not black, is within the bounds of the world and has no other bit on it.
 */
 func (bit *Bit) IsRightClear() bool {
-	if error_occured != nil {
+	if !bit.start_action("isrightclear") {
 		return false
 	}
 	add_bit_state(bit, "is right clear")
 	switch bit.face {
 	case BitUp:
-		return bit.world[bit.y][bit.x+1].color != Black || bit.x+1 >= len(bit.world)
+		return bit.is_clear(bit.x+1, bit.y)
 	case BitDown:
-		return bit.world[bit.y][bit.x-1].color != Black || bit.x-1 < 0
+		return bit.is_clear(bit.x-1, bit.y)
 	case BitLeft:
-		return bit.world[bit.y-1][bit.x].color != Black || bit.y-1 < 0
+		return bit.is_clear(bit.x, bit.y-1)
 	case BitRight:
-		return bit.world[bit.y+1][bit.x].color != Black || bit.y+1 >= len(bit.world)
+		return bit.is_clear(bit.x, bit.y+1)
 	}
 	return false
 }
 
 /*
    This function checks if the square to the left of bit is clear.
-   Meaning that it is not black and is within the bounds of the world.
+   Meaning that it is not black, is within the bounds of the world and has no other bit on it.
 */
 func (bit *Bit) IsLeftClear() bool {
-	if error_occured != nil {
+	if !bit.start_action("isleftclear") {
 		return false
 	}
 	add_bit_state(bit, "is left clear")
 	switch bit.face {
 	case BitUp:
-		return bit.world[bit.y][bit.x-1].color != Black || bit.x-1 < 0
+		return bit.is_clear(bit.x-1, bit.y)
 	case BitDown:
-		return bit.world[bit.y][bit.x+1].color != Black || bit.x-1 >= len(bit.world)
+		return bit.is_clear(bit.x+1, bit.y)
 	case BitLeft:
-		return bit.world[bit.y+1][bit.x].color != Black || bit.y+1 >= len(bit.world)
+		return bit.is_clear(bit.x, bit.y+1)
 	case BitRight:
-		return bit.world[bit.y-1][bit.x].color != Black || bit.y-1 < 0
+		return bit.is_clear(bit.x, bit.y-1)
 	}
 	return false
 }
 
+/*
+   Helper function that returns the color of the square next to bit on the given side,
+   which is "front", "left" or "right". Squares outside of the world are Edge.
+*/
+func (bit *Bit) look(side string) Color {
+	var face string = bit.face
+	if side == "left" {
+		switch face {
+		case BitUp:
+			face = BitLeft
+		case BitDown:
+			face = BitRight
+		case BitLeft:
+			face = BitDown
+		case BitRight:
+			face = BitUp
+		}
+	} else if side == "right" {
+		switch face {
+		case BitUp:
+			face = BitRight
+		case BitDown:
+			face = BitLeft
+		case BitLeft:
+			face = BitUp
+		case BitRight:
+			face = BitDown
+		}
+	}
+	var x, y int = bit.x, bit.y
+	switch face {
+	case BitUp:
+		y--
+	case BitDown:
+		y++
+	case BitLeft:
+		x--
+	case BitRight:
+		x++
+	}
+	if y < 0 || y >= len(bit.world) || x < 0 || x >= len(bit.world[y]) {
+		return Edge
+	}
+	return bit.world[y][x].color
+}
+
+/*
+   This function returns the color of the square in front of bit.
+   It returns Edge if the square is outside of the world and White if bit has stopped because of an error.
+*/
+func (bit *Bit) FrontColor() Color {
+	if !bit.start_action("frontcolor") {
+		return White
+	}
+	add_bit_state(bit, "front color")
+	return bit.look("front")
+}
+
+/*
+   This function returns the color of the square to the left of bit.
+   It returns Edge if the square is outside of the world and White if bit has stopped because of an error.
+*/
+func (bit *Bit) LeftColor() Color {
+	if !bit.start_action("leftcolor") {
+		return White
+	}
+	add_bit_state(bit, "left color")
+	return bit.look("left")
+}
+
+/*
+   This function returns the color of the square to the right of bit.
+   It returns Edge if the square is outside of the world and White if bit has stopped because of an error.
+*/
+func (bit *Bit) RightColor() Color {
+	if !bit.start_action("rightcolor") {
+		return White
+	}
+	add_bit_state(bit, "right color")
+	return bit.look("right")
+}
+
+/*
+   This function checks if the square in front of bit is red.
+*/
+func (bit *Bit) IsFrontRed() bool {
+	if !bit.start_action("isfrontred") {
+		return false
+	}
+	add_bit_state(bit, "is front red")
+	return bit.look("front") == Red
+}
+
+/*
+   This function checks if the square in front of bit is blue.
+*/
+func (bit *Bit) IsFrontBlue() bool {
+	if !bit.start_action("isfrontblue") {
+		return false
+	}
+	add_bit_state(bit, "is front blue")
+	return bit.look("front") == Blue
+}
+
+/*
+   This function checks if the square in front of bit is green.
+*/
+func (bit *Bit) IsFrontGreen() bool {
+	if !bit.start_action("isfrontgreen") {
+		return false
+	}
+	add_bit_state(bit, "is front green")
+	return bit.look("front") == Green
+}
+
+/*
+   This function checks if the square to the left of bit is red.
+*/
+func (bit *Bit) IsLeftRed() bool {
+	if !bit.start_action("isleftred") {
+		return false
+	}
+	add_bit_state(bit, "is left red")
+	return bit.look("left") == Red
+}
+
+/*
+   This function checks if the square to the left of bit is blue.
+*/
+func (bit *Bit) IsLeftBlue() bool {
+	if !bit.start_action("isleftblue") {
+		return false
+	}
+	add_bit_state(bit, "is left blue")
+	return bit.look("left") == Blue
+}
+
+/*
+   This function checks if the square to the left of bit is green.
+*/
+func (bit *Bit) IsLeftGreen() bool {
+	if !bit.start_action("isleftgreen") {
+		return false
+	}
+	add_bit_state(bit, "is left green")
+	return bit.look("left") == Green
+}
+
+/*
+   This function checks if the square to the right of bit is red.
+*/
+func (bit *Bit) IsRightRed() bool {
+	if !bit.start_action("isrightred") {
+		return false
+	}
+	add_bit_state(bit, "is right red")
+	return bit.look("right") == Red
+}
+
+/*
+   This function checks if the square to the right of bit is blue.
+*/
+func (bit *Bit) IsRightBlue() bool {
+	if !bit.start_action("isrightblue") {
+		return false
+	}
+	add_bit_state(bit, "is right blue")
+	return bit.look("right") == Blue
+}
+
+/*
+   This function checks if the square to the right of bit is green.
+*/
+func (bit *Bit) IsRightGreen() bool {
+	if !bit.start_action("isrightgreen") {
+		return false
+	}
+	add_bit_state(bit, "is right green")
+	return bit.look("right") == Green
+}
+
 /*
    This function creates a new snapshot of the current state of the world.
    It takes a string as a parameter which is the name of the snapshot.
 */
 func (bit *Bit) Snapshot(name string) {
-	if error_occured != nil {
+	if bit.session.err != nil {
 		return
 	}
 	add_bit_state(bit, "snapshot " + name)
-	bit_snapshots = append(bit_snapshots, bit.steps)
-	bit_snapshot_names = append(bit_snapshot_names, name)
+	bit.session.snapshots = append(bit.session.snapshots, len(bit.session.states) - 1)
+	bit.session.snapshot_names = append(bit.session.snapshot_names, name)
 }
 
 /*
    This function checks to see if the current state of the world matches the final state of the world.
+   The number of objects on every square must match too.
+   Wildcard squares in the final world match any color and any number of objects,
+   and if the final world has no bit then bit can be anywhere.
 */
 func (bit *Bit) Compare() {
-	var matches bool = true
-
-	if error_occured != nil {
+	if bit.session.err != nil {
 		return
 	}
 	
+	if bit.matches_final() {
+		add_bit_state(bit, "Success!   ")
+	} else {
+		add_bit_state(bit, "Error: Does not match")
+	}
+}
+
+/*
+   Helper function that checks if the current state of the world matches the final state of the world.
+   When the goal world lists its bits by id every bit must end on the square of the goal bit with its id.
+*/
+func (bit *Bit) matches_final() bool {
+	var matches bool = true
+
+	var bit_anywhere bool = !world_has_bit(bit.final_world)
 	for i := range bit.world {
 		for j := range bit.world[i] {
-			if bit.world[i][j].color != bit.final_world[i][j].color || bit.world[i][j].has_bit != bit.final_world[i][j].has_bit {
+			if bit.final_world[i][j].color != Wildcard && bit.world[i][j].color != bit.final_world[i][j].color {
+				matches = false
+				break
+			}
+			if bit.final_world[i][j].color != Wildcard && bit.world[i][j].objects != bit.final_world[i][j].objects {
+				matches = false
+				break
+			}
+			if !bit_anywhere && bit.world[i][j].has_bit != bit.final_world[i][j].has_bit {
 				matches = false
 				break
 			}
 		}
 	}
-	if matches {
-		add_bit_state(bit, "Success!   ")
-	} else {
-		add_bit_state(bit, "Error: Does not match")
+	for _, member := range bit.session.team {
+		if member.final_face != "" && member.face != member.final_face {
+			matches = false
+		}
+		if x, y, ok := bit.session.final_position_of(member.id); ok && (member.x != x || member.y != y) {
+			matches = false
+		}
 	}
+	return matches
 }
@@ -0,0 +1,177 @@
+package bit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseColorMarkers(t *testing.T) {
+	for _, name := range []string{"any", "edge"} {
+		if _, err := ParseColor(name); !errors.Is(err, ErrInvalidColor) {
+			t.Errorf("ParseColor(%q) got %v, want ErrInvalidColor", name, err)
+		}
+	}
+	if color, err := ParseColor("Red"); err != nil || color != Red {
+		t.Errorf("ParseColor(\"Red\") got %v %v, want red", color, err)
+	}
+}
+
+func TestMatchesFinalBitIDs(t *testing.T) {
+	start, err := ParseWorld(strings.NewReader("r\n---\n---\nbit: alice 0 0\nbit: bob 0 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		goal    string
+		matches bool
+	}{
+		{"r\n---\n---\nbit: alice 1 0\nbit: bob 1 1\n", true},
+		{"r\n---\n---\nbit: alice 1 1\nbit: bob 1 0\n", false},
+	} {
+		goal, err := ParseWorld(strings.NewReader(test.goal))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bits, err := NewBits(start, goal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bits[0].Move()
+		bits[1].Move()
+		if bits[0].matches_final() != test.matches {
+			t.Errorf("goal %q: got %v, want %v", test.goal, !test.matches, test.matches)
+		}
+	}
+}
+
+func TestStepLimit(t *testing.T) {
+	world, err := ParseWorld(strings.NewReader("r\n---\n0 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bit, err := NewBit(world, world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bit.SetStepLimit(3)
+	for i := 0; i < 10 && bit.Err() == nil; i++ {
+		bit.Left()
+	}
+	if !errors.Is(bit.Err(), ErrStepLimit) {
+		t.Fatalf("got %v, want ErrStepLimit", bit.Err())
+	}
+	bit.Left()
+	if steps := len(bit.Session().Actions()); steps != 5 {
+		t.Errorf("got %d steps, want the initial state, 3 steps and the error", steps)
+	}
+}
+
+func TestLoopDetection(t *testing.T) {
+	world, err := ParseWorld(strings.NewReader("r\n---\n0 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bit, err := NewBit(world, world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bit.SetLoopDetection(2)
+	for i := 0; i < 20 && bit.Err() == nil; i++ {
+		bit.Left()
+	}
+	if !errors.Is(bit.Err(), ErrInfiniteLoop) {
+		t.Fatalf("got %v, want ErrInfiniteLoop", bit.Err())
+	}
+}
+
+func TestObjects(t *testing.T) {
+	bit := new_test_bit(t, "r\n---\n0 0\nobjects: 0 0 2\n")
+	if err := bit.TryDrop(); !errors.Is(err, ErrNotHolding) {
+		t.Errorf("got %v, want ErrNotHolding", err)
+	}
+	bit.PickUp()
+	bit.PickUp()
+	if bit.ObjectsHere() != 0 || !bit.IsHoldingObject() {
+		t.Fatalf("got %d objects here, want both objects held", bit.ObjectsHere())
+	}
+	if err := bit.TryPickUp(); !errors.Is(err, ErrNoObject) {
+		t.Errorf("got %v, want ErrNoObject", err)
+	}
+	bit.Move()
+	bit.Drop()
+	if bit.ObjectsHere() != 1 || !bit.IsHoldingObject() {
+		t.Errorf("got %d objects here, want 1 and one held", bit.ObjectsHere())
+	}
+	bit.Drop()
+	if bit.ObjectsHere() != 2 || bit.IsHoldingObject() {
+		t.Errorf("got %d objects here, want 2 and none held", bit.ObjectsHere())
+	}
+	if bit.Err() != nil {
+		t.Error(bit.Err())
+	}
+}
+
+func TestCompareObjects(t *testing.T) {
+	start, err := ParseWorld(strings.NewReader("r\n---\n0 0\nobjects: 0 0 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		goal    string
+		matches bool
+	}{
+		{"r\n---\n1 0\nobjects: 0 0 1\nobjects: 1 0 1\n", true},
+		{"r\n---\n1 0\nobjects: 0 0 1\nobjects: 1 0 2\n", false},
+		{"r\n---\n1 0\nobjects: 1 0 1\n", false},
+		{"r\n??-\n1 0\n", true},
+	} {
+		goal, err := ParseWorld(strings.NewReader(test.goal))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bit, err := NewBit(start, goal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bit.PickUp()
+		bit.Move()
+		bit.Drop()
+		if bit.matches_final() != test.matches {
+			t.Errorf("goal %q: got %v, want %v", test.goal, !test.matches, test.matches)
+		}
+	}
+}
+
+func TestLookAhead(t *testing.T) {
+	// Bit is in the middle of the world, red is above it, blue to the right, green below and black to the left.
+	world_text := "%c\n-r-\nxgb\n-g-\n1 1\n"
+	for _, test := range []struct {
+		face  rune
+		front Color
+		left  Color
+		right Color
+	}{
+		{'u', Red, Black, Blue},
+		{'r', Blue, Red, Green},
+		{'d', Green, Blue, Black},
+		{'l', Black, Green, Red},
+	} {
+		bit := new_test_bit(t, fmt.Sprintf(world_text, test.face))
+		if front, left, right := bit.FrontColor(), bit.LeftColor(), bit.RightColor(); front != test.front || left != test.left || right != test.right {
+			t.Errorf("facing %c: got %v %v %v, want %v %v %v", test.face, front, left, right, test.front, test.left, test.right)
+		}
+	}
+
+	bit := new_test_bit(t, "u\nrb\n-g\n0 0\n")
+	if front, left, right := bit.FrontColor(), bit.LeftColor(), bit.RightColor(); front != Edge || left != Edge || right != Blue {
+		t.Errorf("in the corner: got %v %v %v, want edge edge blue", front, left, right)
+	}
+	if !bit.IsRightBlue() || bit.IsFrontRed() || bit.IsLeftGreen() {
+		t.Error("the color checks don't agree with the colors")
+	}
+	if world := bit.World(); world.At(-1, 0) != Edge || world.At(0, 2) != Edge || world.At(1, 1) != Green {
+		t.Errorf("got %v %v %v, want edge edge green", world.At(-1, 0), world.At(0, 2), world.At(1, 1))
+	}
+}
@@ -0,0 +1,103 @@
+package bit
+
+import (
+	"errors"
+)
+
+// ErrInvalidCheckpoint is used when bit is asked to restore a checkpoint from another session.
+var ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+
+/*
+   This struct is a saved state of the world that bit can go back to with Restore.
+   It holds the color and objects of every square, the position, direction and objects
+   held by every bit of the session and the states seen by loop detection when it was made.
+*/
+type Checkpoint struct {
+	session     *Session
+	world       [][]Square
+	team        []checkpoint_bit
+	seen_states map[uint64][]int
+}
+
+/*
+   This struct is the state of one bit in a checkpoint.
+*/
+type checkpoint_bit struct {
+	x       int
+	y       int
+	face    string
+	holding int
+}
+
+/*
+   This function saves the current state of the world so that bit can go back to it with Restore.
+   Making a checkpoint isn't a step, but going back to one is.
+*/
+func (bit *Bit) Checkpoint() *Checkpoint {
+	session := bit.session
+	checkpoint := &Checkpoint{session: session, world: copy_squares(bit.world), seen_states: copy_seen_states(session.seen_states)}
+	for _, member := range session.team {
+		checkpoint.team = append(checkpoint.team, checkpoint_bit{x: member.x, y: member.y, face: member.face, holding: member.holding})
+	}
+	return checkpoint
+}
+
+/*
+   This function puts the world back the way it was when the checkpoint was made.
+   The position and direction of bit, the objects it holds and the colors and objects of every square
+   are restored, and so are the other bits that share the world.
+   The timeline isn't rewound, a "restore" step is added so the gui shows the jump back.
+   This is what a search such as depth-first search needs to back out of a path that didn't work.
+   Loop detection is rewound to the checkpoint too, so the states bit went through after the checkpoint
+   don't count when bit goes through them again after backing out. The restore itself isn't counted
+   by loop detection since backtracking comes back to a checkpoint on purpose, but it is counted by the step limit.
+   Restoring a checkpoint made by a bit of another session stops bit with an error wrapping ErrInvalidCheckpoint.
+*/
+func (bit *Bit) Restore(checkpoint *Checkpoint) {
+	if !bit.start_action("restore") {
+		return
+	}
+	session := bit.session
+	if checkpoint == nil || checkpoint.session != session {
+		stop_with_error(action_error(ErrInvalidCheckpoint, "Checkpoint is from another world"), bit)
+		return
+	}
+	for i := range bit.world {
+		copy(bit.world[i], checkpoint.world[i])
+	}
+	for i, member := range session.team {
+		saved := checkpoint.team[i]
+		member.x = saved.x
+		member.y = saved.y
+		member.face = saved.face
+		member.holding = saved.holding
+	}
+	session.seen_states = copy_seen_states(checkpoint.seen_states)
+	// The hash is set before the step is added so that check_loop skips the restore.
+	if session.loop_repeats > 0 {
+		session.last_state_hash = session.hash_state(bit.world)
+	}
+	add_bit_state(bit, "restore")
+}
+
+/*
+   This function works like Restore but returns the error if the checkpoint is from another session.
+   The error wraps ErrInvalidCheckpoint and bit can keep going.
+*/
+func (bit *Bit) TryRestore(checkpoint *Checkpoint) error {
+	return bit.try_action(func() {
+		bit.Restore(checkpoint)
+	})
+}
+
+/*
+   Helper function that copies the states seen by loop detection.
+   The steps of each state are capped so that appending to them never changes the copy they came from.
+*/
+func copy_seen_states(seen_states map[uint64][]int) map[uint64][]int {
+	dest := make(map[uint64][]int, len(seen_states))
+	for hash, steps := range seen_states {
+		dest[hash] = steps[:len(steps):len(steps)]
+	}
+	return dest
+}
@@ -0,0 +1,86 @@
+package bit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRestoreRewindsWorld(t *testing.T) {
+	world, err := ParseWorld(strings.NewReader("r\n---\n---\nbit: alice 0 0\nbit: bob 2 1 u\nobjects: 1 0 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bits, err := NewBits(world, world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := bits[0], bits[1]
+	checkpoint := alice.Checkpoint()
+
+	alice.Paint("red")
+	alice.Move()
+	alice.PickUp()
+	alice.Left()
+	bob.Move()
+	bob.Right()
+	if alice.Err() != nil || bob.Err() != nil {
+		t.Fatalf("got %v and %v before the restore", alice.Err(), bob.Err())
+	}
+
+	alice.Restore(checkpoint)
+	if alice.Err() != nil {
+		t.Fatal(alice.Err())
+	}
+	if alice.x != 0 || alice.y != 0 || alice.face != BitRight || alice.holding != 0 {
+		t.Errorf("alice is at %d %d facing %s holding %d, want 0 0 facing r holding 0", alice.x, alice.y, alice.face, alice.holding)
+	}
+	if bob.x != 2 || bob.y != 1 || bob.face != BitUp {
+		t.Errorf("bob is at %d %d facing %s, want 2 1 facing u", bob.x, bob.y, bob.face)
+	}
+	current := alice.World()
+	if current.At(0, 0) != White {
+		t.Errorf("got %v at 0 0, want the paint rewound", current.At(0, 0))
+	}
+	if current.ObjectsAt(1, 0) != 2 {
+		t.Errorf("got %d objects at 1 0, want 2", current.ObjectsAt(1, 0))
+	}
+	actions := alice.Session().actions
+	if last := actions[len(actions)-1]; last != "alice: restore" {
+		t.Errorf("last step is %q, want a restore step", last)
+	}
+}
+
+func TestRestoreBacktracking(t *testing.T) {
+	bit := new_test_bit(t, "r\n---\n---\n---\n1 1\n")
+	bit.SetLoopDetection(2)
+	checkpoint := bit.Checkpoint()
+	// Try every direction from the middle a few times, backing out after each move.
+	for round := 0; round < 3; round++ {
+		for turns := 1; turns <= 4; turns++ {
+			for i := 0; i < turns; i++ {
+				bit.Left()
+			}
+			bit.Move()
+			bit.Restore(checkpoint)
+		}
+	}
+	if bit.Err() != nil {
+		t.Fatalf("got %v, want backtracking not to be a loop", bit.Err())
+	}
+}
+
+func TestTryRestoreOtherWorld(t *testing.T) {
+	bit := new_test_bit(t, "r\n--\n0 0\n")
+	other := new_test_bit(t, "r\n--\n0 0\n")
+	if err := bit.TryRestore(other.Checkpoint()); !errors.Is(err, ErrInvalidCheckpoint) {
+		t.Fatalf("got %v, want ErrInvalidCheckpoint", err)
+	}
+	if bit.Err() != nil {
+		t.Fatalf("got %v, want bit to keep going", bit.Err())
+	}
+	bit.Restore(nil)
+	if !errors.Is(bit.Err(), ErrInvalidCheckpoint) {
+		t.Fatalf("got %v, want Restore to stop bit", bit.Err())
+	}
+}
@@ -0,0 +1,331 @@
+package bit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/*
+   These errors describe what kind of problem was found while parsing a course manifest.
+*/
+var (
+	ErrMissingID     = errors.New("exercise has no id")
+	ErrDuplicateID   = errors.New("duplicate exercise id")
+	ErrMissingWorlds = errors.New("exercise needs either a file or a start and a goal")
+)
+
+/*
+   This struct represents a course, an ordered list of exercises described by a manifest file.
+   The paths of the exercises are relative to the directory of the manifest.
+   A course loaded from a file system has fsys set, otherwise dir is a directory of the operating system.
+*/
+type Course struct {
+	Title   string
+	Entries []*CourseEntry
+	fsys    fs.FS
+	dir     string
+}
+
+/*
+   This struct represents one exercise in a course manifest.
+   An entry either names a single exercise File or a Start and a Goal world file.
+   Title, Hints, AllowedActions, DisabledActions and StepBudget override the ones in the exercise file when they are set.
+*/
+type CourseEntry struct {
+	ID              string
+	Title           string
+	File            string
+	Start           string
+	Goal            string
+	AllowedActions  []string
+	DisabledActions []string
+	StepBudget      int
+	Hints           []string
+	course          *Course
+}
+
+/*
+   This function parses a course manifest.
+   The manifest starts with an optional 'title:' for the course followed by an [exercise <id>] section
+   for every exercise, in the order they should be done.
+   The fields of a section are 'title:', 'file:', 'start:', 'goal:', 'actions:', 'disable:', 'steps:' and 'hint:',
+   they work the same way as in an exercise file. Lines starting with '#' are comments.
+   Paths are resolved from the current directory, use LoadCourse or LoadCourseFS to resolve them
+   from the directory of the manifest.
+
+Example:
+   title: Unit 1
+   [exercise paint-red]
+   title: Paint it red
+   start: worlds/paint-red-start.txt
+   goal: worlds/paint-red-end.txt
+   actions: Move, Left, Right, Paint
+   steps: 50
+   hint: Use IsFrontClear to know when to stop.
+   [exercise maze]
+   file: exercises/maze.txt
+
+*/
+func ParseCourse(reader io.Reader) (*Course, error) {
+	course := &Course{fsys: nil, dir: "."}
+	scanner := bufio.NewScanner(reader)
+	var entry *CourseEntry = nil
+	var entry_line int = 0
+	var line_num int = 0
+	ids := map[string]bool{}
+
+	for scanner.Scan() {
+		line_num++
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			if err := check_course_entry(entry, entry_line); err != nil {
+				return nil, err
+			}
+			fields := strings.Fields(trimmed[1 : len(trimmed)-1])
+			if len(fields) == 0 || strings.ToLower(fields[0]) != "exercise" {
+				return nil, &ParseError{Line: line_num, Err: ErrUnknownSection, Detail: trimmed}
+			}
+			if len(fields) != 2 {
+				return nil, &ParseError{Line: line_num, Err: ErrMissingID, Detail: trimmed}
+			}
+			if ids[fields[1]] {
+				return nil, &ParseError{Line: line_num, Err: ErrDuplicateID, Detail: fields[1]}
+			}
+			ids[fields[1]] = true
+			entry = &CourseEntry{ID: fields[1], course: course}
+			entry_line = line_num
+			course.Entries = append(course.Entries, entry)
+			continue
+		}
+
+		key, value, found := strings.Cut(trimmed, ":")
+		if !found {
+			return nil, &ParseError{Line: line_num, Column: 1, Err: ErrUnknownField, Detail: trimmed}
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if entry == nil {
+			if key != "title" {
+				return nil, &ParseError{Line: line_num, Column: 1, Err: ErrUnknownField, Detail: key}
+			}
+			course.Title = value
+			continue
+		}
+		switch key {
+		case "title":
+			entry.Title = value
+		case "file":
+			entry.File = value
+		case "start":
+			entry.Start = value
+		case "goal":
+			entry.Goal = value
+		case "hint":
+			entry.Hints = append(entry.Hints, value)
+		case "actions":
+			actions, err := parse_actions(value)
+			if err != nil {
+				return nil, &ParseError{Line: line_num, Err: err}
+			}
+			entry.AllowedActions = append(entry.AllowedActions, actions...)
+		case "disable":
+			actions, err := parse_actions(value)
+			if err != nil {
+				return nil, &ParseError{Line: line_num, Err: err}
+			}
+			entry.DisabledActions = append(entry.DisabledActions, actions...)
+		case "steps":
+			steps, err := strconv.Atoi(value)
+			if err != nil || steps < 0 {
+				return nil, &ParseError{Line: line_num, Err: ErrInvalidSteps, Detail: strconv.Quote(value)}
+			}
+			entry.StepBudget = steps
+		default:
+			return nil, &ParseError{Line: line_num, Column: 1, Err: ErrUnknownField, Detail: key}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := check_course_entry(entry, entry_line); err != nil {
+		return nil, err
+	}
+	return course, nil
+}
+
+/*
+   Helper function that checks that a course entry names either an exercise file or a start and a goal.
+*/
+func check_course_entry(entry *CourseEntry, line_num int) error {
+	if entry == nil {
+		return nil
+	}
+	only_file := entry.File != "" && entry.Start == "" && entry.Goal == ""
+	only_worlds := entry.File == "" && entry.Start != "" && entry.Goal != ""
+	if !only_file && !only_worlds {
+		return &ParseError{Line: line_num, Err: ErrMissingWorlds, Detail: entry.ID}
+	}
+	return nil
+}
+
+/*
+   This function loads a course manifest from a file.
+   The paths in the manifest are relative to the directory of the manifest, they may also
+   go up out of it such as '../worlds/start.txt' or be absolute.
+*/
+func LoadCourse(file_name string) (*Course, error) {
+	file, err := os.Open(file_name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	course, err := ParseCourse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file_name, err)
+	}
+	course.dir = filepath.Dir(file_name)
+	return course, nil
+}
+
+/*
+   This function loads a course manifest from a file system such as an embed.FS.
+   The paths in the manifest are relative to the directory of the manifest and must stay
+   inside the file system, see fs.ValidPath.
+*/
+func LoadCourseFS(fsys fs.FS, file_name string) (*Course, error) {
+	file, err := fsys.Open(file_name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	course, err := ParseCourse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file_name, err)
+	}
+	course.fsys = fsys
+	course.dir = path.Dir(file_name)
+	return course, nil
+}
+
+/*
+   This function loads every exercise of the course in order.
+   It stops at the first exercise that can't be loaded.
+*/
+func (course *Course) Exercises() ([]*Exercise, error) {
+	exercises := make([]*Exercise, 0, len(course.Entries))
+	for _, entry := range course.Entries {
+		exercise, err := entry.Load()
+		if err != nil {
+			return nil, err
+		}
+		exercises = append(exercises, exercise)
+	}
+	return exercises, nil
+}
+
+/*
+   This function returns the entry of the course with the given id, or nil if there is none.
+*/
+func (course *Course) Entry(id string) *CourseEntry {
+	for _, entry := range course.Entries {
+		if entry.ID == id {
+			return entry
+		}
+	}
+	return nil
+}
+
+/*
+   Helper function that reads an exercise at a path of the manifest.
+*/
+func (course *Course) read_exercise(name string) (*Exercise, error) {
+	if course.fsys != nil {
+		return read_exercise_fs(course.fsys, path.Join(course.dir, name))
+	}
+	return read_exercise(course.os_path(name))
+}
+
+/*
+   Helper function that loads a world at a path of the manifest.
+*/
+func (course *Course) load_world(name string) (*World, error) {
+	if course.fsys != nil {
+		return load_world_fs(course.fsys, path.Join(course.dir, name))
+	}
+	return load_world(course.os_path(name))
+}
+
+/*
+   Helper function that turns a path of the manifest into a path of the operating system.
+*/
+func (course *Course) os_path(name string) string {
+	name = filepath.FromSlash(name)
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(course.dir, name)
+}
+
+/*
+   This function loads the exercise of a course entry.
+   When the entry has a start and a goal, like GetBit bit can be facing any direction at the end.
+*/
+func (entry *CourseEntry) Load() (*Exercise, error) {
+	var exercise *Exercise
+	if entry.File != "" {
+		loaded, err := entry.course.read_exercise(entry.File)
+		if err != nil {
+			return nil, fmt.Errorf("exercise %s: %w", entry.ID, err)
+		}
+		exercise = loaded
+	} else {
+		start, err := entry.course.load_world(entry.Start)
+		if err != nil {
+			return nil, fmt.Errorf("exercise %s: %w", entry.ID, err)
+		}
+		goal, err := entry.course.load_world(entry.Goal)
+		if err != nil {
+			return nil, fmt.Errorf("exercise %s: %w", entry.ID, err)
+		}
+		if err := ValidateWorlds(start, goal); err != nil {
+			return nil, fmt.Errorf("exercise %s: %w", entry.ID, err)
+		}
+		goal.face = ""
+		for i := range goal.bits {
+			goal.bits[i].face = ""
+		}
+		exercise = &Exercise{Start: start, Goal: goal}
+	}
+
+	exercise.ID = entry.ID
+	if entry.Title != "" {
+		exercise.Title = entry.Title
+	}
+	if entry.Hints != nil {
+		exercise.Hints = entry.Hints
+	}
+	if entry.AllowedActions != nil {
+		exercise.AllowedActions = entry.AllowedActions
+	}
+	if entry.DisabledActions != nil {
+		exercise.DisabledActions = entry.DisabledActions
+	}
+	if entry.StepBudget != 0 {
+		exercise.StepBudget = entry.StepBudget
+	}
+	return exercise, nil
+}
@@ -0,0 +1,35 @@
+package bit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCourseSharedWorlds(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"worlds/start.txt": "r\n---\n0 0\n",
+		"worlds/goal.txt":  "r\n--r\n2 0\n",
+		"unit1/course.txt": "title: Unit 1\n[exercise paint]\nstart: ../worlds/start.txt\ngoal: ../worlds/goal.txt\n",
+	}
+	for name, text := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	course, err := LoadCourse(filepath.Join(dir, "unit1", "course.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercises, err := course.Exercises()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exercises) != 1 || exercises[0].Goal.At(2, 0) != Red {
+		t.Errorf("got %v, want the exercise from the shared worlds", exercises)
+	}
+}
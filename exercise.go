@@ -0,0 +1,340 @@
+package bit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*
+   These errors describe what kind of problem was found while parsing an exercise.
+*/
+var (
+	ErrUnknownSection = errors.New("unknown section")
+	ErrUnknownField   = errors.New("unknown field")
+	ErrMissingSection = errors.New("missing section")
+	ErrUnknownAction  = errors.New("unknown action")
+	ErrInvalidSteps   = errors.New("invalid step budget")
+)
+
+// The names of the actions that an exercise can allow, in the form used by start_action.
+var action_names = []string{
+	"move", "left", "right", "paint", "erase", "getcolor", "isred", "isblue", "isgreen",
+	"isfrontclear", "isrightclear", "isleftclear", "pickup", "drop", "isholdingobject", "objectshere",
+	"frontcolor", "leftcolor", "rightcolor", "isfrontred", "isfrontblue", "isfrontgreen",
+	"isleftred", "isleftblue", "isleftgreen", "isrightred", "isrightblue", "isrightgreen", "restore", "goto",
+}
+
+// Bit methods that are checked as another action, PaintColor is allowed by 'paint' and ColorHere by 'getcolor'.
+var action_aliases = map[string]string{
+	"paintcolor": "paint",
+	"colorhere":  "getcolor",
+}
+
+/*
+   This struct represents an exercise.
+   It has a title, a description and hints that are shown to the student,
+   the world that bit starts in and the world that bit must reach.
+   The direction line of the goal world is the direction bit must be facing at the end.
+   AllowedActions lists the actions bit may take, nil means every action is allowed.
+   DisabledActions lists actions bit may not take even if AllowedActions is nil, such as GoTo for beginners.
+   StepBudget is the most steps bit may take, 0 means there is no limit, see SetStepLimit.
+   ID is set when the exercise is loaded from a course.
+*/
+type Exercise struct {
+	ID             string
+	Title          string
+	Description    string
+	Hints          []string
+	AllowedActions  []string
+	DisabledActions []string
+	StepBudget      int
+	Start           *World
+	Goal            *World
+}
+
+/*
+   This function parses an exercise.
+   The format of an exercise is a header of fields followed by a [start] and a [goal] section.
+   Each section is a world in the same format as load_world.
+   The fields are 'title:', 'description:', 'hint:', 'actions:', 'disable:' and 'steps:'.
+   'description:' and 'hint:' can be repeated, description lines are joined by newlines.
+   'actions:' is a comma separated list of the Bit methods the exercise allows, such as 'Move, Left, IsFrontClear'.
+   'disable:' is a comma separated list of the Bit methods the exercise doesn't allow, such as 'GoTo'.
+   'steps:' is the step budget of the exercise.
+   'legend:' adds a color for both worlds, in the same way as a legend line of a world.
+   Lines starting with '#' are comments.
+   The start and goal worlds are checked with ValidateWorlds.
+
+Example:
+   title: Paint it red
+   description: Move to the end of the row
+   description: and paint the last square red.
+   hint: Use IsFrontClear to know when to stop.
+   [start]
+   r
+   -----
+   0 0
+   [goal]
+   r
+   ----r
+   4 0
+
+*/
+func ParseExercise(reader io.Reader) (*Exercise, error) {
+	scanner := bufio.NewScanner(reader)
+	exercise := &Exercise{}
+	var description []string
+	var section string = ""
+	var sections = map[string]*strings.Builder{}
+	var section_lines = map[string]int{}
+	var line_num int = 0
+	var legend []string
+
+	for scanner.Scan() {
+		line_num++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.ToLower(strings.TrimSpace(trimmed[1 : len(trimmed)-1]))
+			if section != "start" && section != "goal" {
+				return nil, &ParseError{Line: line_num, Err: ErrUnknownSection, Detail: trimmed}
+			}
+			sections[section] = &strings.Builder{}
+			section_lines[section] = line_num
+			continue
+		}
+		if section != "" {
+			sections[section].WriteString(line)
+			sections[section].WriteString("\n")
+			continue
+		}
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(trimmed, ":")
+		if !found {
+			return nil, &ParseError{Line: line_num, Column: 1, Err: ErrUnknownField, Detail: trimmed}
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "title":
+			exercise.Title = value
+		case "description":
+			description = append(description, value)
+		case "hint":
+			exercise.Hints = append(exercise.Hints, value)
+		case "actions":
+			actions, err := parse_actions(value)
+			if err != nil {
+				return nil, &ParseError{Line: line_num, Err: err}
+			}
+			exercise.AllowedActions = append(exercise.AllowedActions, actions...)
+		case "disable":
+			actions, err := parse_actions(value)
+			if err != nil {
+				return nil, &ParseError{Line: line_num, Err: err}
+			}
+			exercise.DisabledActions = append(exercise.DisabledActions, actions...)
+		case "steps":
+			steps, err := strconv.Atoi(value)
+			if err != nil || steps < 0 {
+				return nil, &ParseError{Line: line_num, Err: ErrInvalidSteps, Detail: strconv.Quote(value)}
+			}
+			exercise.StepBudget = steps
+		case "legend":
+			if err := parse_legend(value, map[rune]Color{}, map[Color]custom_color{}); err != nil {
+				return nil, &ParseError{Line: line_num, Err: err}
+			}
+			legend = append(legend, "legend: "+value)
+		default:
+			return nil, &ParseError{Line: line_num, Column: 1, Err: ErrUnknownField, Detail: key}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	exercise.Description = strings.Join(description, "\n")
+
+	for _, name := range []string{"start", "goal"} {
+		text, ok := sections[name]
+		if !ok {
+			return nil, &ParseError{Line: line_num + 1, Err: ErrMissingSection, Detail: "[" + name + "]"}
+		}
+		// The legend of the header is put in front of both worlds so they can use its colors.
+		var prefix string = ""
+		for _, line := range legend {
+			prefix += line + "\n"
+		}
+		world, err := ParseWorld(strings.NewReader(prefix + text.String()))
+		if err != nil {
+			var parse_err *ParseError
+			if errors.As(err, &parse_err) {
+				parse_err.Line += section_lines[name] - len(legend)
+			}
+			return nil, err
+		}
+		if name == "start" {
+			exercise.Start = world
+		} else {
+			exercise.Goal = world
+		}
+	}
+	if err := ValidateWorlds(exercise.Start, exercise.Goal); err != nil {
+		return nil, err
+	}
+	return exercise, nil
+}
+
+/*
+   Helper function that reads an exercise from a file.
+*/
+func read_exercise(file_name string) (*Exercise, error) {
+	file, err := os.Open(file_name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parse_exercise_file(file, file_name)
+}
+
+/*
+   Helper function that reads an exercise from a file in a file system.
+*/
+func read_exercise_fs(fsys fs.FS, file_name string) (*Exercise, error) {
+	file, err := fsys.Open(file_name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parse_exercise_file(file, file_name)
+}
+
+/*
+   Helper function that parses an exercise and adds the file name to any error.
+*/
+func parse_exercise_file(reader io.Reader, file_name string) (*Exercise, error) {
+	exercise, err := ParseExercise(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file_name, err)
+	}
+	return exercise, nil
+}
+
+/*
+   This function returns a bit for the exercise in the given file.
+   The title and description of the exercise are shown in the gui.
+   Unlike GetBit, bit must also be facing the direction of the goal world for Compare to succeed.
+*/
+func LoadExercise(file_name string) (*Bit, error) {
+	exercise, err := read_exercise(file_name)
+	if err != nil {
+		return nil, err
+	}
+	return exercise.GetBit(), nil
+}
+
+/*
+   This function works like LoadExercise but loads the exercise from a file system such as an embed.FS.
+*/
+func LoadExerciseFS(fsys fs.FS, file_name string) (*Bit, error) {
+	exercise, err := read_exercise_fs(fsys, file_name)
+	if err != nil {
+		return nil, err
+	}
+	return exercise.GetBit(), nil
+}
+
+/*
+   This function returns a new bit that starts in the start world of the exercise.
+   Each call returns a bit with its own copy of the start world.
+   If the start world has several bits this is the first one, use GetBits to get all of them.
+*/
+func (exercise *Exercise) GetBit() *Bit {
+	return exercise.GetBits()[0]
+}
+
+/*
+   This function returns a new bit for every bit of the start world of the exercise.
+   The bits share their copy of the start world, see GetBits.
+*/
+func (exercise *Exercise) GetBits() []*Bit {
+	bits := setup_bits(exercise.Start, exercise.Goal, true)
+	session := bits[0].session
+	session.exercise = exercise
+	if exercise.StepBudget > 0 {
+		session.step_limit = exercise.StepBudget
+	}
+	if exercise.AllowedActions != nil {
+		session.allowed_actions = make(map[string]bool)
+		for _, action := range exercise.AllowedActions {
+			session.allowed_actions[normalize_action(action)] = true
+		}
+	}
+	if exercise.DisabledActions != nil {
+		if session.allowed_actions == nil {
+			session.allowed_actions = make(map[string]bool)
+			for _, action := range action_names {
+				session.allowed_actions[action] = true
+			}
+		}
+		for _, action := range exercise.DisabledActions {
+			delete(session.allowed_actions, normalize_action(action))
+		}
+	}
+	return bits
+}
+
+/*
+   Helper function that turns the name of a Bit method such as 'IsFrontClear' or 'is_front_clear'
+   into the form used by start_action. Methods that are checked as another action are turned into that action.
+*/
+func normalize_action(action string) string {
+	name := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(action), "_", ""))
+	if alias, ok := action_aliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+/*
+   Helper function that parses a comma separated list of actions.
+   It returns an error naming the first action that bit doesn't have.
+*/
+func parse_actions(value string) ([]string, error) {
+	var actions []string
+	for _, action := range strings.Split(value, ",") {
+		if strings.TrimSpace(action) == "" {
+			continue
+		}
+		name := normalize_action(action)
+		var found bool = false
+		for _, known := range action_names {
+			if known == name {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAction, strings.TrimSpace(action))
+		}
+		actions = append(actions, name)
+	}
+	return actions, nil
+}
+
+/*
+   This function returns the exercise that bit was loaded from.
+   It returns nil if bit was not loaded from an exercise.
+*/
+func (bit *Bit) Exercise() *Exercise {
+	return bit.session.exercise
+}
@@ -0,0 +1,41 @@
+package bit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExerciseStepBudget(t *testing.T) {
+	exercise, err := ParseExercise(strings.NewReader("title: Spin\nsteps: 4\n[start]\nr\n---\n0 0\n[goal]\nr\n---\n0 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exercise.StepBudget != 4 {
+		t.Fatalf("got a step budget of %d, want 4", exercise.StepBudget)
+	}
+	bit := exercise.GetBit()
+	for i := 0; i < 10 && bit.Err() == nil; i++ {
+		bit.Left()
+	}
+	if !errors.Is(bit.Err(), ErrStepLimit) {
+		t.Errorf("got %v, want ErrStepLimit", bit.Err())
+	}
+}
+
+func TestExerciseActionAliases(t *testing.T) {
+	exercise, err := ParseExercise(strings.NewReader("title: Paint\nactions: PaintColor, ColorHere\n[start]\nr\n--\n0 0\n[goal]\nr\nr-\n0 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bit := exercise.GetBit()
+	if color, err := bit.TryColorHere(); color != White || err != nil {
+		t.Errorf("got %v %v, want ColorHere to be allowed", color, err)
+	}
+	if err := bit.TryPaintColor(Red); err != nil {
+		t.Errorf("got %v, want PaintColor to be allowed", err)
+	}
+	if err := bit.TryMove(); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("got %v, want Move to not be allowed", err)
+	}
+}
@@ -0,0 +1,124 @@
+package bit
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+/*
+   These errors describe why a world couldn't be generated.
+*/
+var (
+	ErrInvalidSize    = errors.New("world size must be at least 1x1")
+	ErrInvalidDensity = errors.New("wall density must be between 0 and 1")
+	ErrTooManySquares = errors.New("not enough squares for the requested colors")
+)
+
+/*
+   This function type describes how a generated start world is turned into a goal world.
+   It is called for every square of the start world with its position and color and returns the color
+   the square must have in the goal world. Returning Wildcard means the square can be any color.
+   Black squares are never passed to the rule and the rule should not return Black.
+*/
+type ColorRule func(x int, y int, color Color) Color
+
+/*
+   This struct holds the parameters of a generated world.
+   WallDensity is the fraction of squares that are black, between 0 and 1.
+   Red, Blue and Green are the number of squares of each color.
+   GoalRule is used to make the goal world, if it is nil no goal world is made.
+*/
+type GeneratorOptions struct {
+	Width       int
+	Height      int
+	WallDensity float64
+	Red         int
+	Blue        int
+	Green       int
+	GoalRule    ColorRule
+}
+
+/*
+   This function generates a random world.
+   The same seed and options always give the same world.
+   Bit is placed on a random square that isn't black and faces a random direction.
+   If options.GoalRule is set the goal world is made by applying it to every square of the start world,
+   in the goal world bit can end anywhere facing any direction. Otherwise the goal world is nil.
+   With a GoalRule the worlds can be passed to NewBit or used in a WorldPair, a nil goal world is
+   rejected with ErrMissingWorld.
+*/
+func GenerateWorld(seed int64, options GeneratorOptions) (*World, *World, error) {
+	if options.Width < 1 || options.Height < 1 {
+		return nil, nil, fmt.Errorf("%w: got %dx%d", ErrInvalidSize, options.Width, options.Height)
+	}
+	if options.WallDensity < 0 || options.WallDensity > 1 {
+		return nil, nil, fmt.Errorf("%w: got %v", ErrInvalidDensity, options.WallDensity)
+	}
+	var total int = options.Width * options.Height
+	var walls int = int(options.WallDensity * float64(total))
+	if walls > total-1 {
+		walls = total - 1
+	}
+	if options.Red < 0 || options.Blue < 0 || options.Green < 0 || walls+options.Red+options.Blue+options.Green > total {
+		return nil, nil, fmt.Errorf("%w: %d walls, %d red, %d blue and %d green in %d squares", ErrTooManySquares, walls, options.Red, options.Blue, options.Green, total)
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	squares := make([][]Square, options.Height)
+	for i := range squares {
+		squares[i] = make([]Square, options.Width)
+	}
+
+	// Shuffle every position once and hand them out in order so no square is used twice.
+	positions := random.Perm(total)
+	var next int = 0
+	place := func(count int, color Color) {
+		for k := 0; k < count; k++ {
+			position := positions[next]
+			next++
+			squares[position/options.Width][position%options.Width].color = color
+		}
+	}
+	place(walls, Black)
+	place(options.Red, Red)
+	place(options.Blue, Blue)
+	place(options.Green, Green)
+
+	// Bit can start on any square that isn't black, including colored ones.
+	open := positions[walls:]
+	start := open[random.Intn(len(open))]
+	faces := []string{BitUp, BitDown, BitLeft, BitRight}
+	world := &World{
+		face:    faces[random.Intn(len(faces))],
+		x:       start % options.Width,
+		y:       start / options.Width,
+		squares: squares,
+	}
+	world.squares[world.y][world.x].has_bit = true
+
+	if options.GoalRule == nil {
+		return world, nil, nil
+	}
+	return world, apply_color_rule(world, options.GoalRule), nil
+}
+
+/*
+   Helper function that makes a goal world by applying a color rule to every square of a world.
+   Black squares stay black so that the walls of the two worlds match.
+   In the goal world bit can end anywhere facing any direction.
+*/
+func apply_color_rule(world *World, rule ColorRule) *World {
+	goal := &World{face: "", x: -1, y: -1, squares: make([][]Square, len(world.squares))}
+	for i, row := range world.squares {
+		goal.squares[i] = make([]Square, len(row))
+		for j, square := range row {
+			if square.color == Black {
+				goal.squares[i][j].color = Black
+				continue
+			}
+			goal.squares[i][j].color = rule(j, i, square.color)
+		}
+	}
+	return goal
+}
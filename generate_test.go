@@ -0,0 +1,44 @@
+package bit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateWorldSameSeed(t *testing.T) {
+	options := GeneratorOptions{Width: 8, Height: 6, WallDensity: 0.2, Red: 3, Blue: 2, Green: 1, GoalRule: func(x int, y int, color Color) Color {
+		return Red
+	}}
+	start, goal, err := GenerateWorld(42, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start_again, goal_again, err := GenerateWorld(42, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(start, start_again) || !reflect.DeepEqual(goal, goal_again) {
+		t.Error("the same seed gave different worlds")
+	}
+	other, _, err := GenerateWorld(43, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(start, other) {
+		t.Error("seeds 42 and 43 gave the same world")
+	}
+}
+
+func TestGenerateWorldWithoutGoal(t *testing.T) {
+	start, goal, err := GenerateWorld(1, GeneratorOptions{Width: 4, Height: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goal != nil {
+		t.Fatal("got a goal world without a GoalRule")
+	}
+	if _, err := NewBit(start, goal); !errors.Is(err, ErrMissingWorld) {
+		t.Errorf("got %v, want ErrMissingWorld", err)
+	}
+}
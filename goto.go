@@ -0,0 +1,120 @@
+package bit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnreachable is used when GoTo can't find a path to the square.
+var ErrUnreachable = errors.New("unreachable")
+
+// The offsets of the squares next to a square, in the order GoTo tries them.
+var move_offsets = [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}}
+
+// The direction bit must face to move by each offset.
+var move_faces = map[[2]int]string{
+	{0, -1}: BitUp,
+	{0, 1}:  BitDown,
+	{-1, 0}: BitLeft,
+	{1, 0}:  BitRight,
+}
+
+/*
+   This function moves bit to the square at x and y along a shortest path around black squares and other bits.
+   The path is walked with Move, Left and Right, so every step is shown in the gui and counts toward the step limit.
+   It returns an error wrapping ErrUnreachable if there is no path, in which case bit doesn't move and isn't stopped.
+   Otherwise it returns the error that stopped bit on the way, or nil.
+   GoTo can be turned off for an exercise with 'disable: GoTo', and an exercise that lists its actions
+   only allows GoTo if it lists GoTo too. The steps it takes are allowed even if the exercise doesn't allow
+   Move, Left and Right, so an exercise can hide moving square by square behind GoTo.
+*/
+func (bit *Bit) GoTo(x int, y int) error {
+	if !bit.start_action("goto") {
+		return bit.session.err
+	}
+	path := bit.find_path(x, y)
+	if path == nil {
+		return fmt.Errorf("%w: (%d, %d)", ErrUnreachable, x, y)
+	}
+	bit.session.navigating = true
+	defer func() {
+		bit.session.navigating = false
+	}()
+	for _, square := range path {
+		bit.turn_to(move_faces[[2]int{square[0] - bit.x, square[1] - bit.y}])
+		bit.Move()
+		if bit.session.err != nil {
+			return bit.session.err
+		}
+	}
+	return nil
+}
+
+/*
+   Helper function that finds a shortest path from bit to the square at x and y with a breadth first search.
+   It returns the squares of the path after the one bit is on, an empty path if bit is already there
+   and nil if there is no path.
+*/
+func (bit *Bit) find_path(x int, y int) [][2]int {
+	if x == bit.x && y == bit.y {
+		return [][2]int{}
+	}
+	if !bit.is_clear(x, y) {
+		return nil
+	}
+	previous := map[[2]int][2]int{}
+	start := [2]int{bit.x, bit.y}
+	queue := [][2]int{start}
+	previous[start] = start
+	for len(queue) > 0 {
+		square := queue[0]
+		queue = queue[1:]
+		if square == [2]int{x, y} {
+			var path [][2]int
+			for ; square != start; square = previous[square] {
+				path = append([][2]int{square}, path...)
+			}
+			return path
+		}
+		for _, offset := range move_offsets {
+			next := [2]int{square[0] + offset[0], square[1] + offset[1]}
+			if _, seen := previous[next]; seen || !bit.is_clear(next[0], next[1]) {
+				continue
+			}
+			previous[next] = square
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
+
+/*
+   Helper function that turns bit to face the given direction, turning around with two rights.
+*/
+func (bit *Bit) turn_to(face string) {
+	switch {
+	case bit.face == face:
+	case turn_left(bit.face) == face:
+		bit.Left()
+	case turn_left(face) == bit.face:
+		bit.Right()
+	default:
+		bit.Right()
+		bit.Right()
+	}
+}
+
+/*
+   Helper function that returns the direction to the left of the given direction.
+*/
+func turn_left(face string) string {
+	switch face {
+	case BitUp:
+		return BitLeft
+	case BitLeft:
+		return BitDown
+	case BitDown:
+		return BitRight
+	}
+	return BitUp
+}
@@ -0,0 +1,74 @@
+package bit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGoToShortestPath(t *testing.T) {
+	bit := new_test_bit(t, "r\n-x--\n-x-x\n---x\n0 0\n")
+	if err := bit.GoTo(2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if x, y := bit.World().BitPosition(); x != 2 || y != 0 {
+		t.Fatalf("got (%d, %d), want (2, 0)", x, y)
+	}
+	var moves int = 0
+	for _, action := range bit.Session().Actions() {
+		if strings.TrimSpace(action) == "move" {
+			moves++
+		}
+	}
+	if moves != 6 {
+		t.Errorf("got %d moves, want 6", moves)
+	}
+	if err := bit.GoTo(3, 2); !errors.Is(err, ErrUnreachable) || bit.Err() != nil {
+		t.Errorf("got %v, want ErrUnreachable without stopping bit", err)
+	}
+}
+
+func TestGoToExerciseActions(t *testing.T) {
+	text := "title: Go\n%s\n[start]\nr\n---\n---\n0 0\n[goal]\nr\n---\n---\n2 1\n"
+	exercise, err := ParseExercise(strings.NewReader(strings.Replace(text, "%s", "actions: GoTo", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bit := exercise.GetBit()
+	if err := bit.GoTo(2, 1); err != nil {
+		t.Errorf("got %v, want GoTo to move without allowing Move", err)
+	}
+	if err := bit.TryMove(); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("got %v, want Move to not be allowed", err)
+	}
+
+	exercise, err = ParseExercise(strings.NewReader(strings.Replace(text, "%s", "disable: GoTo", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bit = exercise.GetBit()
+	bit.Move()
+	if err := bit.GoTo(2, 1); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("got %v, want GoTo to be disabled", err)
+	}
+}
+
+func TestGoToDisabledByCourse(t *testing.T) {
+	fsys := fstest.MapFS{
+		"unit.txt":  {Data: []byte("title: Unit 1\n[exercise walk]\nstart: start.txt\ngoal: goal.txt\ndisable: GoTo\n")},
+		"start.txt": {Data: []byte("r\n---\n0 0\n")},
+		"goal.txt":  {Data: []byte("r\n---\n2 0\n")},
+	}
+	course, err := LoadCourseFS(fsys, "unit.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercise, err := course.Entry("walk").Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exercise.GetBit().GoTo(2, 0); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("got %v, want GoTo to be disabled", err)
+	}
+}
@@ -0,0 +1,247 @@
+package bit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/*
+   This struct is the JSON form of a world.
+   Cells are stored row by row using the color names "white", "black", "red", "blue" and "green".
+   Facing is one of "up", "down", "left" and "right".
+   A goal may also use the color "any" for squares that can be any color, the facing "any"
+   and anywhere set to true if bit can end anywhere.
+   Goal is only used at the top level and holds the world that bit must reach.
+   Legend lists the colors that aren't built in, each with the character used in world files and
+   the display color used in the gui, like the legend lines of a world file.
+   A world with several bits lists them in bits, each with an id, bit is then the first of them.
+   A bit in bits without a facing faces the direction of bit.
+   Objects lists the squares that have objects on them and how many.
+
+Example:
+   {
+     "width": 3,
+     "height": 1,
+     "cells": [["white", "red", "black"]],
+     "bit": {"x": 0, "y": 0, "facing": "right"},
+     "goal": {
+       "width": 3,
+       "height": 1,
+       "cells": [["red", "red", "black"]],
+       "bit": {"x": 1, "y": 0, "facing": "right"}
+     }
+   }
+*/
+type json_world struct {
+	Width   int            `json:"width"`
+	Height  int            `json:"height"`
+	Cells   [][]string     `json:"cells"`
+	Bit     json_bit       `json:"bit"`
+	Bits    []json_bit     `json:"bits,omitempty"`
+	Legend  []json_color   `json:"legend,omitempty"`
+	Objects []json_objects `json:"objects,omitempty"`
+	Goal    *json_world    `json:"goal,omitempty"`
+}
+
+/*
+   This struct is the JSON form of a color added by a legend.
+*/
+type json_color struct {
+	Char    string `json:"char"`
+	Name    string `json:"name"`
+	Display string `json:"display"`
+}
+
+/*
+   This struct is the JSON form of the objects on a square.
+*/
+type json_objects struct {
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	Count int `json:"count"`
+}
+
+/*
+   This struct is the JSON form of the position and direction of bit.
+*/
+type json_bit struct {
+	ID       string `json:"id,omitempty"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	Facing   string `json:"facing"`
+	Anywhere bool   `json:"anywhere,omitempty"`
+}
+
+// The names of the directions as used in the JSON format.
+var face_names = map[string]string{
+	BitUp:    "up",
+	BitDown:  "down",
+	BitLeft:  "left",
+	BitRight: "right",
+	"":       "any",
+}
+
+/*
+   This function writes a world as JSON.
+   The goal world may be nil if there is no goal.
+*/
+func EncodeWorldJSON(writer io.Writer, world *World, goal *World) error {
+	value := world_to_json(world)
+	if goal != nil {
+		value.Goal = world_to_json(goal)
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+/*
+   This function reads a world written as JSON.
+   It returns the world and the goal world, the goal world is nil if the JSON has no goal.
+*/
+func DecodeWorldJSON(reader io.Reader) (*World, *World, error) {
+	var value json_world
+	if err := json.NewDecoder(reader).Decode(&value); err != nil {
+		return nil, nil, err
+	}
+	world, err := json_to_world(&value)
+	if err != nil {
+		return nil, nil, err
+	}
+	if value.Goal == nil {
+		return world, nil, nil
+	}
+	goal, err := json_to_world(value.Goal)
+	if err != nil {
+		return nil, nil, fmt.Errorf("goal: %w", err)
+	}
+	return world, goal, nil
+}
+
+/*
+   Helper function that converts a world to its JSON form.
+*/
+func world_to_json(world *World) *json_world {
+	value := &json_world{
+		Height: len(world.squares),
+		Cells:  make([][]string, len(world.squares)),
+		Bit:    json_bit{X: world.x, Y: world.y, Facing: face_names[world.face], Anywhere: world.x < 0},
+	}
+	if len(world.squares) > 0 {
+		value.Width = len(world.squares[0])
+	}
+	for _, member := range world.bits {
+		value.Bits = append(value.Bits, json_bit{ID: member.id, X: member.x, Y: member.y, Facing: face_names[member.face]})
+	}
+	used := map[Color]bool{}
+	for i, row := range world.squares {
+		value.Cells[i] = make([]string, len(row))
+		for j, square := range row {
+			value.Cells[i][j] = square.color.String()
+			if custom, ok := legend_color(world.legend, square.color); ok && !used[square.color] {
+				used[square.color] = true
+				value.Legend = append(value.Legend, json_color{Char: string(custom.char), Name: custom.name, Display: display_name(custom.display)})
+			}
+			if square.objects > 0 {
+				value.Objects = append(value.Objects, json_objects{X: j, Y: i, Count: square.objects})
+			}
+		}
+	}
+	return value
+}
+
+/*
+   Helper function that converts the JSON form of a world to a world.
+   It checks the same things as ParseWorld does for the text format.
+*/
+func json_to_world(value *json_world) (*World, error) {
+	if value.Width <= 0 || value.Height <= 0 {
+		return nil, ErrEmptyWorld
+	}
+	if len(value.Cells) != value.Height {
+		return nil, fmt.Errorf("%w: %d rows, height is %d", ErrRaggedRow, len(value.Cells), value.Height)
+	}
+	legend := map[Color]custom_color{}
+	for _, color := range value.Legend {
+		chars := []rune(color.Char)
+		if len(chars) != 1 {
+			return nil, fmt.Errorf("%w: character %q of %s", ErrInvalidLegend, color.Char, color.Name)
+		}
+		if err := add_legend_color(color.Name, chars[0], color.Display, map[rune]Color{}, legend); err != nil {
+			return nil, err
+		}
+	}
+	face, err := json_face(value.Bit.Facing)
+	if err != nil {
+		return nil, err
+	}
+	world := &World{face: face, x: value.Bit.X, y: value.Bit.Y, squares: make([][]Square, value.Height), legend: legend}
+
+	for i, row := range value.Cells {
+		if len(row) != value.Width {
+			return nil, fmt.Errorf("%w: row %d has %d cells, width is %d", ErrRaggedRow, i, len(row), value.Width)
+		}
+		world.squares[i] = make([]Square, value.Width)
+		for j, name := range row {
+			color, err := json_color_of(name)
+			if err != nil {
+				return nil, fmt.Errorf("%w at (%d, %d)", err, j, i)
+			}
+			world.squares[i][j].color = color
+		}
+	}
+	for _, objects := range value.Objects {
+		if err := world.add_objects(objects.X, objects.Y, objects.Count); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(value.Bits) > 0 {
+		for _, member := range value.Bits {
+			member_face := face
+			if member.Facing != "" {
+				if member_face, err = json_face(member.Facing); err != nil {
+					return nil, err
+				}
+			}
+			if err := world.add_bit(world_bit{id: member.ID, x: member.X, y: member.Y, face: member_face}); err != nil {
+				return nil, err
+			}
+		}
+		return world, nil
+	}
+	if value.Bit.Anywhere {
+		world.x = -1
+		world.y = -1
+		return world, nil
+	}
+	if world.x < 0 || world.y < 0 || world.x >= value.Width || world.y >= value.Height {
+		return nil, fmt.Errorf("%w: (%d, %d) is outside of the %dx%d world", ErrInvalidCoordinates, world.x, world.y, value.Width, value.Height)
+	}
+	world.squares[world.y][world.x].has_bit = true
+	return world, nil
+}
+
+/*
+   Helper function that converts the JSON name of a color to a color.
+   It is the same as ParseColor but also accepts "any" for a Wildcard square.
+*/
+func json_color_of(name string) (Color, error) {
+	if name == color_names[Wildcard] {
+		return Wildcard, nil
+	}
+	return ParseColor(name)
+}
+
+/*
+   Helper function that converts the JSON name of a direction to a bit face.
+*/
+func json_face(name string) (string, error) {
+	for face, face_name := range face_names {
+		if face_name == name {
+			return face, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidDirection, name)
+}
@@ -0,0 +1,57 @@
+package bit
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeWorldJSONWildcard(t *testing.T) {
+	text := `{"width": 2, "height": 1, "cells": [["white", "white"]], "bit": {"x": 0, "y": 0, "facing": "right"},
+		"goal": {"width": 2, "height": 1, "cells": [["any", "red"]], "bit": {"anywhere": true, "facing": "any"}}}`
+	_, goal, err := DecodeWorldJSON(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goal.At(0, 0) != Wildcard {
+		t.Errorf("got %v, want any", goal.At(0, 0))
+	}
+	edge := strings.Replace(text, `"any", "red"`, `"edge", "red"`, 1)
+	if _, _, err := DecodeWorldJSON(strings.NewReader(edge)); !errors.Is(err, ErrInvalidColor) {
+		t.Errorf("got %v, want ErrInvalidColor for edge", err)
+	}
+}
+
+func TestWorldJSONRoundTrip(t *testing.T) {
+	start, err := ParseWorld(strings.NewReader("legend: o orange yellow\nr\n-o---\n--x-b\nbit: alice 0 0\nbit: bob 1 1 u\nobjects: 3 0 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goal, err := ParseWorld(strings.NewReader("?\n??---\n--x-?\n? ?\nobjects: 3 0 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var encoded strings.Builder
+	if err := EncodeWorldJSON(&encoded, start, goal); err != nil {
+		t.Fatal(err)
+	}
+	decoded, decoded_goal, err := DecodeWorldJSON(strings.NewReader(encoded.String()))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, encoded.String())
+	}
+	if !reflect.DeepEqual(start, decoded) {
+		t.Errorf("the start world changed going through JSON:\n%s", encoded.String())
+	}
+	if !reflect.DeepEqual(goal, decoded_goal) {
+		t.Errorf("the goal world changed going through JSON:\n%s", encoded.String())
+	}
+	var saved strings.Builder
+	if err := SaveWorld(&saved, decoded); err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseWorld(strings.NewReader(saved.String()))
+	if err != nil || !reflect.DeepEqual(start, again) {
+		t.Errorf("got %v, want the decoded world to save as the text world:\n%s", err, saved.String())
+	}
+}
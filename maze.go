@@ -0,0 +1,126 @@
+package bit
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+/*
+   This error is returned when the loops of a maze are out of range.
+*/
+var ErrInvalidLoops = errors.New("maze loops must be between 0 and 1")
+
+/*
+   This struct holds the parameters of a generated maze.
+   Columns and Rows are the number of open cells across and down, the world is 2*Columns-1 by 2*Rows-1
+   squares because there is a wall or a passage between every two cells.
+   Loops is the fraction of the walls left inside a perfect maze that are knocked down to make loops,
+   0 makes a perfect maze with exactly one path between any two cells.
+*/
+type MazeOptions struct {
+	Columns int
+	Rows    int
+	Loops   float64
+}
+
+/*
+   This function generates a random maze.
+   The same seed and options always give the same maze.
+   Bit starts in the top left corner facing right and the exit is the bottom right corner, which is painted green.
+   Every cell can be reached from every other cell so there is always a path from bit to the exit.
+   In the goal world bit is on the exit facing any direction and the squares are the same as the start world.
+*/
+func GenerateMaze(seed int64, options MazeOptions) (*World, *World, error) {
+	if options.Columns < 1 || options.Rows < 1 {
+		return nil, nil, fmt.Errorf("%w: got %dx%d cells", ErrInvalidSize, options.Columns, options.Rows)
+	}
+	if options.Loops < 0 || options.Loops > 1 {
+		return nil, nil, fmt.Errorf("%w: got %v", ErrInvalidLoops, options.Loops)
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	var width int = options.Columns*2 - 1
+	var height int = options.Rows*2 - 1
+	squares := make([][]Square, height)
+	for i := range squares {
+		squares[i] = make([]Square, width)
+		for j := range squares[i] {
+			if i%2 != 0 || j%2 != 0 {
+				squares[i][j].color = Black
+			}
+		}
+	}
+
+	carve_maze(squares, options.Columns, options.Rows, random)
+	add_maze_loops(squares, options.Loops, random)
+
+	exit_x := width - 1
+	exit_y := height - 1
+	squares[exit_y][exit_x].color = Green
+
+	start := &World{face: BitRight, x: 0, y: 0, squares: squares}
+	goal := &World{face: "", x: exit_x, y: exit_y, squares: copy_squares(squares)}
+	start.squares[0][0].has_bit = true
+	goal.squares[exit_y][exit_x].has_bit = true
+	return start, goal, nil
+}
+
+/*
+   Helper function that carves a perfect maze with a depth first search.
+   Cells are at the even positions of the squares and the search knocks down the wall between
+   a cell and a neighbor it hasn't visited yet.
+*/
+func carve_maze(squares [][]Square, columns int, rows int, random *rand.Rand) {
+	visited := make([][]bool, rows)
+	for i := range visited {
+		visited[i] = make([]bool, columns)
+	}
+	directions := [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}}
+
+	stack := [][2]int{{0, 0}}
+	visited[0][0] = true
+	for len(stack) > 0 {
+		cell := stack[len(stack)-1]
+		var neighbors [][2]int
+		for _, direction := range directions {
+			x := cell[0] + direction[0]
+			y := cell[1] + direction[1]
+			if x >= 0 && x < columns && y >= 0 && y < rows && !visited[y][x] {
+				neighbors = append(neighbors, [2]int{x, y})
+			}
+		}
+		if len(neighbors) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		next := neighbors[random.Intn(len(neighbors))]
+		squares[cell[1]+next[1]][cell[0]+next[0]].color = White
+		visited[next[1]][next[0]] = true
+		stack = append(stack, next)
+	}
+}
+
+/*
+   Helper function that knocks down some of the walls between two cells to make loops.
+   Only walls that separate two cells are considered, the corners between four cells stay black.
+*/
+func add_maze_loops(squares [][]Square, loops float64, random *rand.Rand) {
+	if loops == 0 {
+		return
+	}
+	var walls [][2]int
+	for i := range squares {
+		for j := range squares[i] {
+			if squares[i][j].color == Black && (i%2 == 0) != (j%2 == 0) {
+				walls = append(walls, [2]int{j, i})
+			}
+		}
+	}
+	random.Shuffle(len(walls), func(a int, b int) {
+		walls[a], walls[b] = walls[b], walls[a]
+	})
+	for _, wall := range walls[:int(loops*float64(len(walls)))] {
+		squares[wall[1]][wall[0]].color = White
+	}
+}
@@ -0,0 +1,79 @@
+package bit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateMazeReachesExit(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		options := MazeOptions{Columns: 1 + int(seed%7), Rows: 1 + int(seed%5), Loops: float64(seed%3) / 4}
+		start, goal, err := GenerateMaze(seed, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bit, err := NewBit(start, goal)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if err := bit.GoTo(goal.BitPosition()); err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if !bit.matches_final() {
+			t.Errorf("seed %d: bit reached the exit but doesn't match the goal", seed)
+		}
+	}
+}
+
+func TestGenerateMazePerfect(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		options := MazeOptions{Columns: 2 + int(seed%6), Rows: 2 + int(seed%4)}
+		start, _, err := GenerateMaze(seed, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var passages int = 0
+		for y := 0; y < start.Height(); y++ {
+			for x := 0; x < start.Width(); x++ {
+				if (x%2 == 0) != (y%2 == 0) && start.At(x, y) != Black {
+					passages++
+				}
+			}
+		}
+		if cells := options.Columns * options.Rows; passages != cells-1 {
+			t.Errorf("seed %d: got %d passages between %d cells, want %d", seed, passages, cells, cells-1)
+		}
+	}
+}
+
+func TestGenerateMazeSameSeed(t *testing.T) {
+	options := MazeOptions{Columns: 6, Rows: 4, Loops: 0.3}
+	start, goal, err := GenerateMaze(7, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start_again, goal_again, err := GenerateMaze(7, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(start, start_again) || !reflect.DeepEqual(goal, goal_again) {
+		t.Error("the same seed gave different mazes")
+	}
+}
+
+func TestGenerateMazeInvalidOptions(t *testing.T) {
+	for _, test := range []struct {
+		options MazeOptions
+		want    error
+	}{
+		{MazeOptions{Columns: 3, Rows: 3, Loops: -0.1}, ErrInvalidLoops},
+		{MazeOptions{Columns: 3, Rows: 3, Loops: 1.5}, ErrInvalidLoops},
+		{MazeOptions{Columns: 0, Rows: 3}, ErrInvalidSize},
+		{MazeOptions{Columns: 3, Rows: -1}, ErrInvalidSize},
+	} {
+		if _, _, err := GenerateMaze(1, test.options); !errors.Is(err, test.want) {
+			t.Errorf("%+v: got %v, want %v", test.options, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package bit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+/*
+   These errors describe why a color couldn't be added to the palette.
+*/
+var (
+	ErrInvalidLegend  = errors.New("invalid legend")
+	ErrColorDefined   = errors.New("color is already defined")
+	ErrUnknownDisplay = errors.New("unknown display color")
+)
+
+/*
+   This struct represents a color that was added to the palette by a legend.
+   It has the name of the color, the character used for it in world files and
+   the ANSI background code used to draw it in the gui.
+   The palette only keeps the character and display the color was first added with,
+   each world keeps the ones of its own legend, see legend_color.
+*/
+type custom_color struct {
+	name    string
+	char    rune
+	display int
+}
+
+// The colors added by legends, the color of custom_colors[i] is first_custom_color + i.
+var custom_colors []custom_color = nil
+
+// Guards custom_colors so that worlds with legends can be loaded from several goroutines.
+var palette_lock sync.RWMutex
+
+// The value of the first color added by a legend.
+const first_custom_color Color = Edge + 1
+
+// The display colors a legend can use and their ANSI background codes.
+var display_codes = map[string]int{
+	"black":   40,
+	"red":     41,
+	"green":   42,
+	"yellow":  43,
+	"blue":    44,
+	"magenta": 45,
+	"cyan":    46,
+	"white":   47,
+}
+
+/*
+   This function adds a new color to the palette, or returns the color if it was already added.
+   The name is used by Paint, GetColor and ParseColor, the char is used for the color when a world is saved
+   and the display is one of "black", "red", "green", "yellow", "blue", "magenta", "cyan" and "white".
+   The char and display are used by worlds that don't have a legend line for the color.
+   It returns an error if the name is one of the built in colors or was added before with a different display.
+   Legend lines don't have that problem, two worlds can draw the same color differently.
+*/
+func DefineColor(name string, char rune, display string) (Color, error) {
+	return define_color(name, char, display, true)
+}
+
+/*
+   Helper function that adds a color to the palette.
+   If strict is false a color that was added before with a different display is returned as it is.
+*/
+func define_color(name string, char rune, display string, strict bool) (Color, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	code, ok := display_codes[strings.ToLower(display)]
+	if !ok {
+		return White, fmt.Errorf("%w: %q", ErrUnknownDisplay, display)
+	}
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return White, fmt.Errorf("%w: color name %q", ErrInvalidLegend, name)
+	}
+	if err := check_legend_char(char); err != nil {
+		return White, err
+	}
+	palette_lock.Lock()
+	defer palette_lock.Unlock()
+	for _, builtin := range color_names {
+		if builtin == name {
+			return White, fmt.Errorf("%w: %s is a built in color", ErrColorDefined, name)
+		}
+	}
+	for i, color := range custom_colors {
+		if color.name != name {
+			continue
+		}
+		if strict && color.display != code {
+			return White, fmt.Errorf("%w: %s is displayed as %s", ErrColorDefined, name, display_name(color.display))
+		}
+		return first_custom_color + Color(i), nil
+	}
+	custom_colors = append(custom_colors, custom_color{name: name, char: char, display: code})
+	return first_custom_color + Color(len(custom_colors)-1), nil
+}
+
+/*
+   Helper function that checks that a character can be used for a color in a world file.
+   The characters of the built in colors, digits and spaces are taken.
+*/
+func check_legend_char(char rune) error {
+	if _, builtin := parse_color(char); builtin || unicode.IsDigit(char) || unicode.IsSpace(char) {
+		return fmt.Errorf("%w: character %q can't be used for a color", ErrInvalidLegend, char)
+	}
+	return nil
+}
+
+/*
+   Helper function that returns the name of an ANSI background code.
+*/
+func display_name(code int) string {
+	for name, display_code := range display_codes {
+		if display_code == code {
+			return name
+		}
+	}
+	return "white"
+}
+
+/*
+   Helper function that returns the color added by a legend, if the color is one.
+*/
+func lookup_custom_color(color Color) (custom_color, bool) {
+	palette_lock.RLock()
+	defer palette_lock.RUnlock()
+	index := int(color - first_custom_color)
+	if index < 0 || index >= len(custom_colors) {
+		return custom_color{}, false
+	}
+	return custom_colors[index], true
+}
+
+/*
+   Helper function that returns the color added by a legend as it is in a world,
+   with the character and display of the legend of the world if it has one for the color.
+*/
+func legend_color(legend map[Color]custom_color, color Color) (custom_color, bool) {
+	if custom, ok := legend[color]; ok {
+		return custom, true
+	}
+	return lookup_custom_color(color)
+}
+
+/*
+   Helper function that returns the ANSI background code used to draw a color added by a legend.
+   Unknown colors are drawn white.
+*/
+func custom_display(color Color, legend map[Color]custom_color) int {
+	if custom, ok := legend_color(legend, color); ok {
+		return custom.display
+	}
+	return 47
+}
+
+/*
+   Helper function that parses a legend line of a world file and adds the color to the legend.
+   A legend line looks like 'legend: o orange yellow', which makes 'o' an orange square drawn yellow.
+   chars maps the characters of the world to their colors and legend keeps the character and display of each color.
+*/
+func parse_legend(value string, chars map[rune]Color, legend map[Color]custom_color) error {
+	fields := strings.Fields(value)
+	if len(fields) != 3 || len([]rune(fields[0])) != 1 {
+		return fmt.Errorf("%w: expected 'legend: <char> <name> <display>'", ErrInvalidLegend)
+	}
+	return add_legend_color(fields[1], []rune(fields[0])[0], fields[2], chars, legend)
+}
+
+/*
+   Helper function that adds a color to the palette and to the legend of a world.
+*/
+func add_legend_color(name string, char rune, display string, chars map[rune]Color, legend map[Color]custom_color) error {
+	color, err := define_color(name, char, display, false)
+	if err != nil {
+		return err
+	}
+	custom, _ := lookup_custom_color(color)
+	custom.char = char
+	custom.display = display_codes[strings.ToLower(display)]
+	chars[char] = color
+	legend[color] = custom
+	return nil
+}
+
+/*
+   Helper function that returns the legend lines needed to save a world that uses colors added by legends.
+   It returns an error if two of the colors use the same character.
+*/
+func legend_lines(squares [][]Square, legend map[Color]custom_color) ([]string, error) {
+	var lines []string
+	used := map[Color]bool{}
+	chars := map[rune]string{}
+	for _, row := range squares {
+		for _, square := range row {
+			custom, ok := legend_color(legend, square.color)
+			if !ok || used[square.color] {
+				continue
+			}
+			used[square.color] = true
+			if other, taken := chars[custom.char]; taken {
+				return nil, fmt.Errorf("%w: %s and %s both use %q", ErrInvalidLegend, other, custom.name, custom.char)
+			}
+			chars[custom.char] = custom.name
+			lines = append(lines, fmt.Sprintf("legend: %c %s %s", custom.char, custom.name, display_name(custom.display)))
+		}
+	}
+	return lines, nil
+}
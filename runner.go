@@ -0,0 +1,116 @@
+package bit
+
+import (
+	"context"
+	"fmt"
+)
+
+/*
+   This struct represents a start world and the goal world that bit must reach from it.
+*/
+type WorldPair struct {
+	Start *World
+	Goal  *World
+}
+
+/*
+   This struct represents the result of running a program on one world pair.
+   Passed is true if bit reached the goal world without an error.
+   Err is the error that stopped bit, or nil if bit didn't make an invalid move, see TryMove.
+   Steps is the number of moves and turns bit made.
+   Session is the session of the run, it can be shown with RunGui.
+*/
+type RunResult struct {
+	Passed  bool
+	Err     error
+	Steps   int
+	Session *Session
+}
+
+/*
+   This function runs a program once for every world pair and returns a result for each pair.
+   Each run starts from a fresh copy of the start world, so runs can't affect each other.
+   The results can be shown with RunGuiResults.
+   If the program panics, the panic is recorded as the error of that run and the next pair is run.
+*/
+func RunWorlds(pairs []WorldPair, program func(*Bit)) ([]*RunResult, error) {
+	return RunWorldsContext(context.Background(), pairs, program)
+}
+
+/*
+   This function works like RunWorlds but stops when the context is cancelled, for example after a timeout.
+   The next action bit takes after the context is cancelled stops the run with a "Cancelled" step,
+   the error of that run wraps the error of the context.
+   It returns the results of the runs so far, including the cancelled one, and the error of the context.
+   A program that never calls a method of bit can't be stopped.
+
+Example:
+   ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+   defer cancel()
+   results, err := bit.RunWorldsContext(ctx, pairs, program)
+*/
+func RunWorldsContext(ctx context.Context, pairs []WorldPair, program func(*Bit)) ([]*RunResult, error) {
+	results := make([]*RunResult, 0, len(pairs))
+	for i, pair := range pairs {
+		if err := ValidateWorlds(pair.Start, pair.Goal); err != nil {
+			return nil, fmt.Errorf("world %d: %w", i+1, err)
+		}
+	}
+	for _, pair := range pairs {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		bit := setup_bits(pair.Start, pair.Goal, false)[0]
+		bit.session.ctx = ctx
+		run_program(bit, program)
+
+		result := &RunResult{Steps: bit.steps, Session: bit.session}
+		if bit.session.err == nil {
+			result.Passed = bit.matches_final()
+			bit.Compare()
+		}
+		result.Err = bit.session.err
+		bit.session.passed = result.Passed
+		results = append(results, result)
+	}
+	return results, ctx.Err()
+}
+
+/*
+   This function runs a program with bit and then compares the world with the final world, see Compare.
+   Once bit is stopped by an error, such as the step limit or loop detection, the next action ends the program,
+   so a loop that never ends doesn't keep going after bit stops.
+   If the program panics, the panic is recorded as the error.
+   It returns the error that stopped bit, or nil, and the steps can then be shown with RunGui.
+
+Example:
+   b.SetStepLimit(100)
+   b.Run(func(b *bit.Bit) {
+      for !b.IsBlue() {
+         b.Left()
+      }
+   })
+   bit.RunGui(b.Session())
+*/
+func (bit *Bit) Run(program func(*Bit)) error {
+	run_program(bit, program)
+	bit.Compare()
+	return bit.session.err
+}
+
+/*
+   Helper function that runs a program and stops bit with an error if the program panics.
+   A program stopped by halt_program already has its error.
+*/
+func run_program(bit *Bit, program func(*Bit)) {
+	bit.session.running = true
+	defer func() {
+		bit.session.running = false
+		if value := recover(); value != nil {
+			if _, halted := value.(halt_signal); !halted && bit.session.err == nil {
+				stop_display_error(fmt.Sprint("panic: ", value), bit)
+			}
+		}
+	}()
+	program(bit)
+}
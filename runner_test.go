@@ -0,0 +1,89 @@
+package bit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunWorldsStopsRunawayProgram(t *testing.T) {
+	world, err := ParseWorld(strings.NewReader("r\n---\n0 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := RunWorlds([]WorldPair{{Start: world, Goal: world}, {Start: world, Goal: world}}, func(bit *Bit) {
+		bit.SetStepLimit(5)
+		for {
+			bit.Left()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, result := range results {
+		if result.Passed || !errors.Is(result.Err, ErrStepLimit) {
+			t.Errorf("world %d: got passed %v with %v, want ErrStepLimit", i, result.Passed, result.Err)
+		}
+	}
+}
+
+func TestRunStopsRunawayLoop(t *testing.T) {
+	world, err := ParseWorld(strings.NewReader("r\n---\n0 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bit, err := NewBit(world, world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bit.SetStepLimit(10)
+	err = bit.Run(func(bit *Bit) {
+		for !bit.IsBlue() {
+			bit.Left()
+		}
+	})
+	if !errors.Is(err, ErrStepLimit) {
+		t.Fatalf("got %v, want ErrStepLimit", err)
+	}
+	if bit.steps > 10 {
+		t.Errorf("bit took %d steps after the limit of 10", bit.steps)
+	}
+	actions := bit.Session().actions
+	if last := actions[len(actions)-1]; !strings.Contains(last, "Step limit exceeded") {
+		t.Errorf("last step is %q, want the step limit error", last)
+	}
+}
+
+func TestRunWorldsContextCancel(t *testing.T) {
+	world, err := ParseWorld(strings.NewReader("r\n---\n0 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := 0
+	pairs := []WorldPair{{Start: world, Goal: world}, {Start: world, Goal: world}, {Start: world, Goal: world}}
+	results, err := RunWorldsContext(ctx, pairs, func(bit *Bit) {
+		runs++
+		bit.Left()
+		cancel()
+		for {
+			bit.Left()
+		}
+	})
+	if err != ctx.Err() || !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want the error of the context", err)
+	}
+	if runs != 1 || len(results) != 1 {
+		t.Fatalf("got %d runs and %d results, want the pairs after the cancel not to run", runs, len(results))
+	}
+	result := results[0]
+	if result.Passed || !errors.Is(result.Err, context.Canceled) {
+		t.Errorf("got passed %v with %v, want context.Canceled", result.Passed, result.Err)
+	}
+	actions := result.Session.actions
+	if last := actions[len(actions)-1]; !strings.HasPrefix(last, "Cancelled") {
+		t.Errorf("last step is %q, want a Cancelled step", last)
+	}
+}
@@ -0,0 +1,148 @@
+package bit
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+/*
+   This struct represents one run of bit.
+   It owns the timeline of the run, the snapshots, the error that stopped bit and the size of the world,
+   so several runs can be kept in the same program without changing each other.
+   Every GetBit, LoadBit, NewBit and GetBits makes a new session that is shared by the bits it returns.
+   Pass sessions to RunGui to view them.
+   The legend of the session is the legends of its start and goal worlds, the start world wins when both have a color.
+
+   Bits from different sessions can be used from different goroutines at the same time, for example
+   by tests run with t.Parallel. The bits of one session, and the session itself, must only be used
+   by one goroutine at a time because they share the world and the timeline.
+*/
+type Session struct {
+	states          []*Bit
+	actions         []string
+	snapshots       []int
+	snapshot_names  []string
+	err             error
+	width           int
+	height          int
+	exercise        *Exercise
+	allowed_actions map[string]bool
+	team            []*Bit
+	final_team      []world_bit
+	step_limit      int
+	loop_repeats    int
+	seen_states     map[uint64][]int
+	last_state_hash uint64
+	running         bool
+	ctx             context.Context
+	passed          bool
+	legend          map[Color]custom_color
+	trying          bool
+	try_err         error
+	navigating      bool
+}
+
+// The session of the last bit that was made, shown by RunGui when it isn't given a session.
+var last_session *Session = nil
+
+// Guards last_session and the defaults of new sessions.
+var session_lock sync.Mutex
+
+/*
+   This function makes an empty session with the default step limit and loop detection.
+*/
+func new_session() *Session {
+	session_lock.Lock()
+	defer session_lock.Unlock()
+	return &Session{
+		states:       make([]*Bit, 1),
+		actions:      make([]string, 1),
+		step_limit:   default_step_limit,
+		loop_repeats: default_loop_repeats,
+		seen_states:  make(map[uint64][]int),
+	}
+}
+
+/*
+   This function returns the session of bit.
+*/
+func (bit *Bit) Session() *Session {
+	return bit.session
+}
+
+/*
+   This function returns the bits of the session in the order they are listed in the world.
+*/
+func (session *Session) Bits() []*Bit {
+	return append([]*Bit(nil), session.team...)
+}
+
+/*
+   This function returns the error that stopped the bits of the session, or nil if none of them made an invalid move.
+*/
+func (session *Session) Err() error {
+	return session.err
+}
+
+/*
+   This function returns the actions taken in the session, starting with "initial state".
+*/
+func (session *Session) Actions() []string {
+	return append([]string(nil), session.actions...)
+}
+
+/*
+   This function returns the position and direction of every bit of the session right now.
+*/
+func (session *Session) snapshot_team() []world_bit {
+	team := make([]world_bit, len(session.team))
+	for i, member := range session.team {
+		team[i] = world_bit{id: member.id, x: member.x, y: member.y, face: member.face}
+	}
+	return team
+}
+
+/*
+   This function returns a hash of the position, direction and objects of every bit
+   and the color and objects of every square.
+*/
+func (session *Session) hash_state(world [][]Square) uint64 {
+	hash := fnv.New64a()
+	for _, member := range session.team {
+		fmt.Fprint(hash, member.x, ",", member.y, ",", member.face, ",", member.holding, ";")
+	}
+	for _, row := range world {
+		for _, square := range row {
+			fmt.Fprint(hash, int(square.color), ",", square.objects, ";")
+		}
+	}
+	return hash.Sum64()
+}
+
+/*
+   Helper function that returns where the bit with the given id must end when the goal world lists its bits by id.
+   It returns false if the goal world has no bit with that id.
+*/
+func (session *Session) final_position_of(id string) (int, int, bool) {
+	for _, member := range session.final_team {
+		if member.id != "" && member.id == id {
+			return member.x, member.y, true
+		}
+	}
+	return -1, -1, false
+}
+
+/*
+   Helper function that returns the direction the bit with the given id must face at the end.
+   A goal world with a single bit gives its direction to whichever bit there is.
+*/
+func (session *Session) final_face_of(id string) string {
+	for _, member := range session.final_team {
+		if member.id == id || len(session.final_team) == 1 {
+			return member.face
+		}
+	}
+	return ""
+}
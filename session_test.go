@@ -0,0 +1,74 @@
+package bit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+/*
+   Runs a program for the start and goal worlds of session i, first with NewBit and then with RunWorlds.
+*/
+func run_parallel_session(t *testing.T, i int) {
+	text := fmt.Sprintf("legend: o shade%d yellow\nr\n-x-\n---\n0 0\n", i)
+	goal_text := fmt.Sprintf("legend: o shade%d yellow\n?\n-xo\n---\n2 0\n", i)
+	start, err := ParseWorld(strings.NewReader(text))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	goal, err := ParseWorld(strings.NewReader(goal_text))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	program := func(bit *Bit) {
+		if err := bit.GoTo(2, 0); err != nil {
+			t.Error(err)
+		}
+		bit.Paint(fmt.Sprint("shade", i))
+	}
+
+	bit, err := NewBit(start, goal)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	program(bit)
+	bit.Compare()
+	if !bit.matches_final() || bit.Err() != nil {
+		t.Errorf("session %d: got %q, want the goal", i, bit.Session().Actions())
+	}
+
+	results, err := RunWorlds([]WorldPair{{Start: start, Goal: goal}}, program)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !results[0].Passed {
+		t.Errorf("session %d: got %v, want the run to pass", i, results[0].Err)
+	}
+}
+
+func TestSessionsInParallel(t *testing.T) {
+	var group sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		group.Add(1)
+		go func(i int) {
+			defer group.Done()
+			run_parallel_session(t, i)
+		}(i)
+	}
+	group.Wait()
+}
+
+func TestSessionsWithParallelTests(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		i := i
+		t.Run(fmt.Sprint("session ", i), func(t *testing.T) {
+			t.Parallel()
+			run_parallel_session(t, i)
+		})
+	}
+}
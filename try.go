@@ -0,0 +1,352 @@
+package bit
+
+import (
+	"errors"
+)
+
+/*
+   These errors describe why bit stopped.
+   The error that stopped bit wraps one of them, so errors.Is can be used to check for them.
+   ErrInvalidColor is used when bit is asked to paint a color that can't be painted.
+   ErrOutOfBounds, ErrBlocked, ErrInvalidColor, ErrNoObject, ErrNotHolding and ErrInvalidCheckpoint can be recovered from,
+   a Try function returns them without stopping bit. The others stop bit even during a Try function.
+*/
+var (
+	ErrOutOfBounds  = errors.New("out of bounds")
+	ErrBlocked      = errors.New("blocked")
+	ErrNoObject     = errors.New("no object to pick up")
+	ErrNotHolding   = errors.New("not holding an object")
+	ErrNotAllowed   = errors.New("action not allowed")
+	ErrStepLimit    = errors.New("step limit exceeded")
+	ErrInfiniteLoop = errors.New("infinite loop")
+)
+
+/*
+   This struct is the error that stopped bit.
+   The message is the one shown in the gui and err is one of the errors above.
+*/
+type bit_error struct {
+	msg string
+	err error
+}
+
+func (e *bit_error) Error() string {
+	return e.msg
+}
+
+func (e *bit_error) Unwrap() error {
+	return e.err
+}
+
+// The errors that a Try function returns without stopping bit.
+var recoverable_errors = []error{ErrOutOfBounds, ErrBlocked, ErrInvalidColor, ErrNoObject, ErrNotHolding, ErrInvalidCheckpoint}
+
+/*
+   Helper function that checks if bit can recover from an error.
+*/
+func is_recoverable(err error) bool {
+	for _, recoverable := range recoverable_errors {
+		if errors.Is(err, recoverable) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+   Helper function that makes the error that stops bit from one of the errors above and the message for the gui.
+*/
+func action_error(err error, msg string) error {
+	return &bit_error{msg: msg, err: err}
+}
+
+/*
+   This function returns the error that stopped bit, or nil if bit hasn't made an invalid move.
+   Errors returned by a Try function that didn't stop bit are not returned.
+*/
+func (bit *Bit) Err() error {
+	return bit.session.err
+}
+
+/*
+   Helper function that takes an action in the way of a Try function.
+   An error that bit can recover from is added to the steps and returned without stopping bit,
+   otherwise the error that stopped bit is returned, or nil.
+*/
+func (bit *Bit) try_action(action func()) error {
+	session := bit.session
+	session.trying = true
+	session.try_err = nil
+	defer func() {
+		session.trying = false
+	}()
+	action()
+	if session.err != nil {
+		return session.err
+	}
+	return session.try_err
+}
+
+/*
+   This function works like Move but returns the error if bit can't move.
+   The error wraps ErrOutOfBounds if bit would leave the world and ErrBlocked if the square is black
+   or has another bit on it, bit stays where it is and can keep going.
+   Like every Try function, if bit already stopped because of an earlier error, that error is returned,
+   and an error such as ErrStepLimit that bit can't recover from stops bit and is returned.
+*/
+func (bit *Bit) TryMove() error {
+	return bit.try_action(bit.Move)
+}
+
+/*
+   This function works like Left but returns the error if bit can't turn.
+*/
+func (bit *Bit) TryLeft() error {
+	return bit.try_action(bit.Left)
+}
+
+/*
+   This function works like Right but returns the error if bit can't turn.
+*/
+func (bit *Bit) TryRight() error {
+	return bit.try_action(bit.Right)
+}
+
+/*
+   This function works like Paint but returns the error if bit can't paint.
+   The error wraps ErrInvalidColor if the color can't be painted.
+*/
+func (bit *Bit) TryPaint(color string) error {
+	return bit.try_action(func() { bit.Paint(color) })
+}
+
+/*
+   This function works like PaintColor but returns the error if bit can't paint.
+   The error wraps ErrInvalidColor if the color can't be painted.
+*/
+func (bit *Bit) TryPaintColor(color Color) error {
+	return bit.try_action(func() { bit.PaintColor(color) })
+}
+
+/*
+   This function works like Erase but returns the error if bit can't erase.
+*/
+func (bit *Bit) TryErase() error {
+	return bit.try_action(bit.Erase)
+}
+
+/*
+   This function works like PickUp but returns the error if bit can't pick up an object.
+   The error wraps ErrNoObject if there are no objects on the square.
+*/
+func (bit *Bit) TryPickUp() error {
+	return bit.try_action(bit.PickUp)
+}
+
+/*
+   This function works like Drop but returns the error if bit can't drop an object.
+   The error wraps ErrNotHolding if bit isn't holding an object.
+*/
+func (bit *Bit) TryDrop() error {
+	return bit.try_action(bit.Drop)
+}
+
+/*
+   This function works like IsHoldingObject but also returns the error that stopped bit, or nil.
+   Checks can't fail on their own, the error is one such as ErrStepLimit or ErrNotAllowed that stops bit.
+   Like every Try function, the result is the zero value when there is an error.
+*/
+func (bit *Bit) TryIsHoldingObject() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsHoldingObject() })
+	return result, err
+}
+
+/*
+   This function works like ObjectsHere but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryObjectsHere() (int, error) {
+	var result int
+	err := bit.try_action(func() { result = bit.ObjectsHere() })
+	return result, err
+}
+
+/*
+   This function works like GetColor but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryGetColor() (string, error) {
+	var result string
+	err := bit.try_action(func() { result = bit.GetColor() })
+	return result, err
+}
+
+/*
+   This function works like ColorHere but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryColorHere() (Color, error) {
+	var result Color
+	err := bit.try_action(func() { result = bit.ColorHere() })
+	return result, err
+}
+
+/*
+   This function works like IsRed but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsRed() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsRed() })
+	return result, err
+}
+
+/*
+   This function works like IsBlue but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsBlue() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsBlue() })
+	return result, err
+}
+
+/*
+   This function works like IsGreen but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsGreen() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsGreen() })
+	return result, err
+}
+
+/*
+   This function works like IsFrontClear but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsFrontClear() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsFrontClear() })
+	return result, err
+}
+
+/*
+   This function works like IsRightClear but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsRightClear() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsRightClear() })
+	return result, err
+}
+
+/*
+   This function works like IsLeftClear but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsLeftClear() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsLeftClear() })
+	return result, err
+}
+
+/*
+   This function works like FrontColor but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryFrontColor() (Color, error) {
+	var result Color
+	err := bit.try_action(func() { result = bit.FrontColor() })
+	return result, err
+}
+
+/*
+   This function works like LeftColor but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryLeftColor() (Color, error) {
+	var result Color
+	err := bit.try_action(func() { result = bit.LeftColor() })
+	return result, err
+}
+
+/*
+   This function works like RightColor but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryRightColor() (Color, error) {
+	var result Color
+	err := bit.try_action(func() { result = bit.RightColor() })
+	return result, err
+}
+
+/*
+   This function works like IsFrontRed but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsFrontRed() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsFrontRed() })
+	return result, err
+}
+
+/*
+   This function works like IsFrontBlue but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsFrontBlue() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsFrontBlue() })
+	return result, err
+}
+
+/*
+   This function works like IsFrontGreen but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsFrontGreen() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsFrontGreen() })
+	return result, err
+}
+
+/*
+   This function works like IsLeftRed but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsLeftRed() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsLeftRed() })
+	return result, err
+}
+
+/*
+   This function works like IsLeftBlue but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsLeftBlue() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsLeftBlue() })
+	return result, err
+}
+
+/*
+   This function works like IsLeftGreen but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsLeftGreen() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsLeftGreen() })
+	return result, err
+}
+
+/*
+   This function works like IsRightRed but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsRightRed() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsRightRed() })
+	return result, err
+}
+
+/*
+   This function works like IsRightBlue but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsRightBlue() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsRightBlue() })
+	return result, err
+}
+
+/*
+   This function works like IsRightGreen but also returns the error that stopped bit, or nil.
+*/
+func (bit *Bit) TryIsRightGreen() (bool, error) {
+	var result bool
+	err := bit.try_action(func() { result = bit.IsRightGreen() })
+	return result, err
+}
@@ -0,0 +1,76 @@
+package bit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func new_test_bit(t *testing.T, text string) *Bit {
+	world, err := ParseWorld(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bit, err := NewBit(world, world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bit
+}
+
+func TestTryRecoverable(t *testing.T) {
+	bit := new_test_bit(t, "r\n-x\n-x\n0 0\n")
+	if err := bit.TryMove(); !errors.Is(err, ErrBlocked) {
+		t.Fatalf("got %v, want ErrBlocked", err)
+	}
+	if err := bit.TryPaint("mauve"); !errors.Is(err, ErrInvalidColor) {
+		t.Fatalf("got %v, want ErrInvalidColor", err)
+	}
+	if err := bit.TryPickUp(); !errors.Is(err, ErrNoObject) {
+		t.Fatalf("got %v, want ErrNoObject", err)
+	}
+	if bit.Err() != nil {
+		t.Fatalf("got %v, want bit to keep going", bit.Err())
+	}
+	if err := bit.TryRight(); err != nil {
+		t.Fatal(err)
+	}
+	if err := bit.TryMove(); err != nil {
+		t.Fatal(err)
+	}
+	if err := bit.TryMove(); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("got %v, want ErrOutOfBounds", err)
+	}
+	actions := bit.Session().Actions()
+	if actions[1] != "Blocked" || actions[len(actions)-1] != "Out of bounds" {
+		t.Errorf("got %q, want the errors in the steps", actions)
+	}
+}
+
+func TestTryStepLimit(t *testing.T) {
+	bit := new_test_bit(t, "r\n--\n0 0\n")
+	bit.SetStepLimit(2)
+	var err error
+	for i := 0; i < 5 && err == nil; i++ {
+		err = bit.TryLeft()
+	}
+	if !errors.Is(err, ErrStepLimit) || !errors.Is(bit.Err(), ErrStepLimit) {
+		t.Errorf("got %v, want ErrStepLimit", err)
+	}
+	if clear, err := bit.TryIsFrontClear(); clear || !errors.Is(err, ErrStepLimit) {
+		t.Errorf("got %v %v, want false and ErrStepLimit", clear, err)
+	}
+}
+
+func TestTrySensing(t *testing.T) {
+	bit := new_test_bit(t, "r\n-r\n0 0\n")
+	if color, err := bit.TryFrontColor(); color != Red || err != nil {
+		t.Errorf("got %v %v, want red", color, err)
+	}
+	if red, err := bit.TryIsRed(); red || err != nil {
+		t.Errorf("got %v %v, want false", red, err)
+	}
+	if color, err := bit.TryGetColor(); color != "white" || err != nil {
+		t.Errorf("got %q %v, want white", color, err)
+	}
+}
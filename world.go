@@ -0,0 +1,751 @@
+package bit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*
+   These errors describe what kind of problem was found while parsing a world.
+   They are wrapped by a ParseError so that errors.Is can be used to check for them.
+*/
+var (
+	ErrEmptyWorld         = errors.New("world is empty")
+	ErrInvalidDirection   = errors.New("invalid direction")
+	ErrInvalidCharacter   = errors.New("invalid character")
+	ErrInvalidColor       = errors.New("invalid color")
+	ErrRaggedRow          = errors.New("row width does not match the first row")
+	ErrMissingCoordinates = errors.New("missing bit coordinates")
+	ErrInvalidCoordinates = errors.New("invalid bit coordinates")
+	ErrTrailingData       = errors.New("unexpected data after bit coordinates")
+	ErrDuplicateBit       = errors.New("duplicate bit id")
+	ErrInvalidObjects     = errors.New("invalid objects")
+)
+
+/*
+   These errors describe why a start world and a goal world don't go together.
+   They are returned by ValidateWorlds wrapped with the cell that caused the problem.
+*/
+var (
+	ErrSizeMismatch    = errors.New("start and goal worlds are different sizes")
+	ErrWallMismatch    = errors.New("walls of the start and goal worlds don't match")
+	ErrBitCount        = errors.New("world has the wrong number of bits")
+	ErrBitOnWall       = errors.New("bit is on a black square")
+	ErrWildcardInStart = errors.New("start world can't have wildcards")
+	ErrMissingWorld    = errors.New("missing world")
+)
+
+/*
+   This struct represents an error found while parsing a world.
+   Line and Column are 1-based and point at the offending character.
+   A Column of 0 means that the error applies to the whole line.
+*/
+type ParseError struct {
+	Line   int
+	Column int
+	Err    error
+	Detail string
+}
+
+func (e *ParseError) Error() string {
+	var msg string = e.Err.Error()
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Column > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, msg)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, msg)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+/*
+   This struct represents a parsed world.
+   It has the direction that bit starts facing, the position of bit and the squares of the world.
+   A world with several bits also has the id, position and direction of each of them in bits,
+   the direction and position of the world are the ones of the first bit.
+   The legend has the character and display of the colors added by the legend lines of the world.
+   Use the methods of World to look at it, a World can't be changed once it is made.
+*/
+type World struct {
+	face    string
+	x       int
+	y       int
+	squares [][]Square
+	bits    []world_bit
+	legend  map[Color]custom_color
+}
+
+/*
+   This struct represents one of the bits of a world that has several bits.
+*/
+type world_bit struct {
+	id   string
+	x    int
+	y    int
+	face string
+}
+
+/*
+   This function returns the number of squares across the world.
+*/
+func (world *World) Width() int {
+	if len(world.squares) == 0 {
+		return 0
+	}
+	return len(world.squares[0])
+}
+
+/*
+   This function returns the number of squares down the world.
+*/
+func (world *World) Height() int {
+	return len(world.squares)
+}
+
+/*
+   This function returns the color of the square at x y, where 0 0 is the top left corner.
+   Squares outside of the world are Edge, the same as FrontColor, LeftColor and RightColor return.
+*/
+func (world *World) At(x int, y int) Color {
+	if y < 0 || y >= len(world.squares) || x < 0 || x >= len(world.squares[y]) {
+		return Edge
+	}
+	return world.squares[y][x].color
+}
+
+/*
+   This function returns the number of objects on the square at x y.
+   Squares outside of the world have no objects.
+*/
+func (world *World) ObjectsAt(x int, y int) int {
+	if y < 0 || y >= len(world.squares) || x < 0 || x >= len(world.squares[y]) {
+		return 0
+	}
+	return world.squares[y][x].objects
+}
+
+/*
+   This function returns the x and y coordinates of bit.
+   It returns -1 -1 for a goal world where bit can end anywhere.
+*/
+func (world *World) BitPosition() (int, int) {
+	return world.x, world.y
+}
+
+/*
+   This function returns the direction bit is facing, one of BitUp, BitDown, BitLeft and BitRight.
+   It returns an empty string for a goal world where bit can face any direction.
+*/
+func (world *World) Facing() string {
+	return world.face
+}
+
+/*
+   This function returns the ids of the bits of a world in the order they were listed.
+   It returns nil for a world that has a single bit without an id.
+*/
+func (world *World) BitIDs() []string {
+	var ids []string
+	for _, member := range world.bits {
+		ids = append(ids, member.id)
+	}
+	return ids
+}
+
+/*
+   This function returns the x and y coordinates of the bit with the given id.
+   It returns -1 -1 if the world has no bit with that id.
+*/
+func (world *World) BitPositionOf(id string) (int, int) {
+	for _, member := range world.bits {
+		if member.id == id {
+			return member.x, member.y
+		}
+	}
+	return -1, -1
+}
+
+/*
+   Helper function that returns every bit of a world, a world with a single bit without an id
+   returns that bit and a world where bit can be anywhere returns nil.
+*/
+func (world *World) team() []world_bit {
+	if world.bits != nil {
+		return world.bits
+	}
+	if world.x < 0 {
+		return nil
+	}
+	return []world_bit{{x: world.x, y: world.y, face: world.face}}
+}
+
+/*
+   Helper function that places a bit on a world that has several bits.
+   It checks that the bit is within the bounds of the world, that its id isn't used by another bit
+   and that no other bit is on the same square.
+*/
+func (world *World) add_bit(member world_bit) error {
+	if member.y < 0 || member.y >= len(world.squares) || member.x < 0 || member.x >= len(world.squares[member.y]) {
+		return fmt.Errorf("%w: (%d, %d) is outside of the %dx%d world", ErrInvalidCoordinates, member.x, member.y, len(world.squares[0]), len(world.squares))
+	}
+	for _, other := range world.bits {
+		if other.id == member.id {
+			return fmt.Errorf("%w: %s", ErrDuplicateBit, member.id)
+		}
+	}
+	if world.squares[member.y][member.x].has_bit {
+		return fmt.Errorf("%w: two bits on (%d, %d)", ErrBitCount, member.x, member.y)
+	}
+	world.squares[member.y][member.x].has_bit = true
+	world.bits = append(world.bits, member)
+	world.face = world.bits[0].face
+	world.x = world.bits[0].x
+	world.y = world.bits[0].y
+	return nil
+}
+
+/*
+   This function returns a copy of the world bit is in right now.
+   Later actions of bit don't change the returned world.
+   When bit shares the world with other bits the returned world has all of them.
+*/
+func (bit *Bit) World() *World {
+	world := make_team_world(bit.session.snapshot_team(), copy_squares(bit.world))
+	world.legend = bit.session.legend
+	return world
+}
+
+/*
+   This function returns a copy of the world that bit must reach.
+*/
+func (bit *Bit) Goal() *World {
+	world := make_team_world(bit.session.final_team, copy_squares(bit.final_world))
+	world.legend = bit.session.legend
+	return world
+}
+
+/*
+   Helper function that converts a direction character to a bit face.
+   '?' means that bit can face any direction and is returned as an empty face.
+*/
+func parse_direction(char rune) (string, bool) {
+	switch char {
+	case '?':
+		return "", true
+	case 'u':
+		return BitUp, true
+	case 'd':
+		return BitDown, true
+	case 'l':
+		return BitLeft, true
+	case 'r':
+		return BitRight, true
+	}
+	return "", false
+}
+
+/*
+   Helper function that converts a grid character to a color.
+*/
+func parse_color(char rune) (Color, bool) {
+	switch char {
+	case '-':
+		return White, true
+	case 'x':
+		return Black, true
+	case 'r':
+		return Red, true
+	case 'b':
+		return Blue, true
+	case 'g':
+		return Green, true
+	case '?':
+		return Wildcard, true
+	}
+	return White, false
+}
+
+/*
+   Helper function that converts a bit face to a direction character.
+*/
+func direction_char(face string) rune {
+	switch face {
+	case BitUp:
+		return 'u'
+	case BitDown:
+		return 'd'
+	case BitLeft:
+		return 'l'
+	case BitRight:
+		return 'r'
+	}
+	return '?'
+}
+
+/*
+   Helper function that converts a color to a grid character.
+   Colors added by legends use the character of the legend of the world.
+*/
+func color_char(color Color, legend map[Color]custom_color) rune {
+	switch color {
+	case Black:
+		return 'x'
+	case Red:
+		return 'r'
+	case Blue:
+		return 'b'
+	case Green:
+		return 'g'
+	case Wildcard:
+		return '?'
+	}
+	if custom, ok := legend_color(legend, color); ok {
+		return custom.char
+	}
+	return '-'
+}
+
+/*
+   This function parses a world in the text grid format.
+   The first line is the direction bit starts facing, followed by the rows of the grid,
+   followed by the x and y coordinates of bit. Coordinates may have any number of digits.
+   Blank lines are ignored.
+   Goal worlds may use '?' for squares that can be any color, '?' as the direction if bit can face any direction
+   and '? ?' as the coordinates if bit can end anywhere.
+   Before the direction there can be legend lines that add colors, 'legend: o orange yellow' makes 'o' an orange
+   square that is drawn yellow in the gui. See DefineColor for the display colors.
+   Instead of the coordinates a world can have several bits, one 'bit: <id> <x> <y> [direction]' line for each.
+   A bit without a direction faces the direction of the world.
+   After the grid there can be lines that put objects on a square, 'objects: 2 0 3' puts 3 objects on the square at 2 0.
+   Any problem is returned as a *ParseError that has the line and column of the problem.
+*/
+func ParseWorld(reader io.Reader) (*World, error) {
+	scanner := bufio.NewScanner(reader)
+	world := &World{x: -1, y: -1, legend: map[Color]custom_color{}}
+	var line_num int = 0
+	var last_line int = 0
+	var done bool = false
+	var have_face bool = false
+	chars := map[rune]Color{}
+
+	for scanner.Scan() {
+		line_num++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		last_line = line_num
+
+		if len(world.squares) > 0 && strings.HasPrefix(strings.TrimSpace(line), "objects:") {
+			if err := world.parse_objects(strings.TrimPrefix(strings.TrimSpace(line), "objects:")); err != nil {
+				return nil, &ParseError{Line: line_num, Err: err}
+			}
+			continue
+		}
+
+		is_bit_line := have_face && strings.HasPrefix(strings.TrimSpace(line), "bit:")
+		if done || (world.bits != nil && !is_bit_line) {
+			return nil, &ParseError{Line: line_num, Err: ErrTrailingData}
+		}
+
+		if !have_face && strings.HasPrefix(strings.TrimSpace(line), "legend:") {
+			if err := parse_legend(strings.TrimPrefix(strings.TrimSpace(line), "legend:"), chars, world.legend); err != nil {
+				return nil, &ParseError{Line: line_num, Err: err}
+			}
+			continue
+		}
+
+		if !have_face {
+			trimmed := strings.TrimSpace(line)
+			face, ok := parse_direction(rune(trimmed[0]))
+			if !ok || len(trimmed) != 1 {
+				return nil, &ParseError{Line: line_num, Column: strings.Index(line, trimmed) + 1, Err: ErrInvalidDirection, Detail: strconv.Quote(trimmed)}
+			}
+			world.face = face
+			have_face = true
+			continue
+		}
+
+		if is_bit_line {
+			if len(world.squares) == 0 {
+				return nil, &ParseError{Line: line_num, Err: ErrEmptyWorld}
+			}
+			member, err := parse_bit_line(strings.TrimPrefix(strings.TrimSpace(line), "bit:"), world.face)
+			if err == nil {
+				err = world.add_bit(member)
+			}
+			if err != nil {
+				return nil, &ParseError{Line: line_num, Err: err}
+			}
+			continue
+		}
+
+		if is_coordinate_line(line) {
+			if len(world.squares) == 0 {
+				return nil, &ParseError{Line: line_num, Err: ErrEmptyWorld}
+			}
+			if err := world.parse_coordinates(line, line_num); err != nil {
+				return nil, err
+			}
+			done = true
+			continue
+		}
+
+		var row []Square
+		for column, char := range []rune(line) {
+			color, ok := parse_color(char)
+			if char_color, found := chars[char]; found {
+				color, ok = char_color, true
+			}
+			if !ok {
+				return nil, &ParseError{Line: line_num, Column: column + 1, Err: ErrInvalidCharacter, Detail: strconv.QuoteRune(char)}
+			}
+			row = append(row, Square{color: color, has_bit: false})
+		}
+		if len(world.squares) > 0 && len(row) != len(world.squares[0]) {
+			return nil, &ParseError{Line: line_num, Err: ErrRaggedRow, Detail: fmt.Sprintf("got %d, want %d", len(row), len(world.squares[0]))}
+		}
+		world.squares = append(world.squares, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !have_face || len(world.squares) == 0 {
+		return nil, &ParseError{Line: line_num + 1, Err: ErrEmptyWorld}
+	}
+	if !done && world.bits == nil {
+		return nil, &ParseError{Line: last_line + 1, Err: ErrMissingCoordinates}
+	}
+
+	if world.x >= 0 && world.bits == nil {
+		world.squares[world.y][world.x].has_bit = true
+	}
+	return world, nil
+}
+
+/*
+   Helper function that checks if a line is the coordinate line of a world, two integers or '? ?'.
+   Any other line is read as a row of the grid so a typo such as '1' is reported as an invalid character.
+*/
+func is_coordinate_line(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) != 2 {
+		return false
+	}
+	if fields[0] == "?" && fields[1] == "?" {
+		return true
+	}
+	for _, field := range fields {
+		if _, err := strconv.Atoi(field); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+   Helper function that parses the coordinate line of a world.
+   It checks that bit is placed within the bounds of the world.
+   The coordinates '? ?' mean that bit can be anywhere and leave the position at -1 -1.
+*/
+func (world *World) parse_coordinates(line string, line_num int) error {
+	if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "?" && fields[1] == "?" {
+		return nil
+	}
+	var values []int
+	var column int = 0
+	for column < len(line) {
+		for column < len(line) && (line[column] == ' ' || line[column] == '\t') {
+			column++
+		}
+		if column >= len(line) {
+			break
+		}
+		start := column
+		for column < len(line) && line[column] != ' ' && line[column] != '\t' {
+			column++
+		}
+		value, err := strconv.Atoi(line[start:column])
+		if err != nil || strings.TrimLeft(line[start:column], "0123456789") != "" {
+			return &ParseError{Line: line_num, Column: start + 1, Err: ErrInvalidCoordinates, Detail: strconv.Quote(line[start:column])}
+		}
+		if len(values) == 2 {
+			return &ParseError{Line: line_num, Column: start + 1, Err: ErrInvalidCoordinates, Detail: "expected only x and y"}
+		}
+		values = append(values, value)
+	}
+	if len(values) != 2 {
+		return &ParseError{Line: line_num, Err: ErrMissingCoordinates, Detail: "expected x and y"}
+	}
+	if values[1] >= len(world.squares) || values[0] >= len(world.squares[0]) {
+		return &ParseError{Line: line_num, Err: ErrInvalidCoordinates, Detail: fmt.Sprintf("(%d, %d) is outside of the %dx%d world", values[0], values[1], len(world.squares[0]), len(world.squares))}
+	}
+	world.x = values[0]
+	world.y = values[1]
+	return nil
+}
+
+/*
+   Helper function that parses the part of an objects line after 'objects:', such as '2 0 3',
+   and puts the objects on the square.
+   Objects can't be put on a black square and each square can only be listed once.
+*/
+func (world *World) parse_objects(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) != 3 {
+		return fmt.Errorf("%w: expected 'objects: <x> <y> <count>'", ErrInvalidObjects)
+	}
+	var values [3]int
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil || value < 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidObjects, field)
+		}
+		values[i] = value
+	}
+	return world.add_objects(values[0], values[1], values[2])
+}
+
+/*
+   Helper function that puts a number of objects on a square of a world.
+*/
+func (world *World) add_objects(x int, y int, count int) error {
+	if y < 0 || y >= len(world.squares) || x < 0 || x >= len(world.squares[y]) {
+		return fmt.Errorf("%w: (%d, %d) is outside of the %dx%d world", ErrInvalidObjects, x, y, len(world.squares[0]), len(world.squares))
+	}
+	if count < 1 {
+		return fmt.Errorf("%w: count at (%d, %d) must be at least 1", ErrInvalidObjects, x, y)
+	}
+	if world.squares[y][x].color == Black {
+		return fmt.Errorf("%w: (%d, %d) is a black square", ErrInvalidObjects, x, y)
+	}
+	if world.squares[y][x].objects > 0 {
+		return fmt.Errorf("%w: (%d, %d) is listed twice", ErrInvalidObjects, x, y)
+	}
+	world.squares[y][x].objects = count
+	return nil
+}
+
+/*
+   Helper function that parses the part of a bit line after 'bit:', such as 'alice 0 2 r'.
+   The bit faces the given direction if the line doesn't have one.
+*/
+func parse_bit_line(value string, face string) (world_bit, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 3 && len(fields) != 4 {
+		return world_bit{}, fmt.Errorf("%w: expected 'bit: <id> <x> <y> [direction]'", ErrMissingCoordinates)
+	}
+	member := world_bit{id: fields[0], face: face}
+	for i, target := range []*int{&member.x, &member.y} {
+		value, err := strconv.Atoi(fields[i+1])
+		if err != nil || value < 0 {
+			return world_bit{}, fmt.Errorf("%w: %q", ErrInvalidCoordinates, fields[i+1])
+		}
+		*target = value
+	}
+	if len(fields) == 4 {
+		direction, ok := parse_direction([]rune(fields[3])[0])
+		if !ok || len([]rune(fields[3])) != 1 {
+			return world_bit{}, fmt.Errorf("%w: %q", ErrInvalidDirection, fields[3])
+		}
+		member.face = direction
+	}
+	return member, nil
+}
+
+/*
+   Helper function that creates a world from the bits of a team and the squares of a world.
+   A team of one bit without an id makes a world with a single bit, an empty team means bit can be anywhere.
+*/
+func make_team_world(team []world_bit, squares [][]Square) *World {
+	if len(team) == 0 {
+		return &World{face: "", x: -1, y: -1, squares: squares}
+	}
+	world := &World{face: team[0].face, x: team[0].x, y: team[0].y, squares: squares}
+	if len(team) > 1 || team[0].id != "" {
+		world.bits = append([]world_bit(nil), team...)
+	}
+	return world
+}
+
+/*
+   Helper function that checks if any square of a world has bit.
+   A goal world without bit means that bit can end anywhere.
+*/
+func world_has_bit(squares [][]Square) bool {
+	for _, row := range squares {
+		for _, square := range row {
+			if square.has_bit {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+   This function writes a world in the text grid format.
+   The output can be read back with ParseWorld or used as a world file for GetBit.
+*/
+func SaveWorld(writer io.Writer, world *World) error {
+	legend, err := legend_lines(world.squares, world.legend)
+	if err != nil {
+		return err
+	}
+	buffer := bufio.NewWriter(writer)
+	for _, line := range legend {
+		fmt.Fprintln(buffer, line)
+	}
+	fmt.Fprintf(buffer, "%c\n", direction_char(world.face))
+	for _, row := range world.squares {
+		for _, square := range row {
+			buffer.WriteRune(color_char(square.color, world.legend))
+		}
+		buffer.WriteString("\n")
+	}
+	if world.bits != nil {
+		for _, member := range world.bits {
+			fmt.Fprintf(buffer, "bit: %s %d %d %c\n", member.id, member.x, member.y, direction_char(member.face))
+		}
+	} else if world.x < 0 {
+		buffer.WriteString("? ?\n")
+	} else {
+		fmt.Fprintf(buffer, "%d %d\n", world.x, world.y)
+	}
+	for i, row := range world.squares {
+		for j, square := range row {
+			if square.objects > 0 {
+				fmt.Fprintf(buffer, "objects: %d %d %d\n", j, i, square.objects)
+			}
+		}
+	}
+	return buffer.Flush()
+}
+
+/*
+   This function writes the current state of bit's world in the text grid format.
+*/
+func (bit *Bit) Save(writer io.Writer) error {
+	return SaveWorld(writer, bit.World())
+}
+
+/*
+   This function writes the state of bit's world at the snapshot with the given name in the text grid format.
+   If there are several snapshots with the same name the first one is used.
+*/
+func (bit *Bit) SaveSnapshot(writer io.Writer, name string) error {
+	for i, snapshot_name := range bit.session.snapshot_names {
+		if snapshot_name == name {
+			state := bit.session.states[bit.session.snapshots[i]]
+			world := make_team_world(state.team, state.world)
+			world.legend = bit.session.legend
+			return SaveWorld(writer, world)
+		}
+	}
+	return fmt.Errorf("no snapshot named %q", name)
+}
+
+/*
+   This function checks that a start world and a goal world can be used together.
+   Both worlds must be the same size, have black squares in the same places and have the same bits,
+   none of them on a black square.
+   Only the goal world may use wildcards, a wildcard square matches a black square and
+   the goal world may leave out bit if bit can end anywhere.
+   The returned error names the cell that caused the problem, or wraps ErrMissingWorld if a world is nil.
+*/
+func ValidateWorlds(start *World, goal *World) error {
+	if start == nil {
+		return fmt.Errorf("%w: start", ErrMissingWorld)
+	}
+	if goal == nil {
+		return fmt.Errorf("%w: goal", ErrMissingWorld)
+	}
+	if len(start.squares) != len(goal.squares) || len(start.squares[0]) != len(goal.squares[0]) {
+		return fmt.Errorf("%w: start is %dx%d, goal is %dx%d", ErrSizeMismatch, len(start.squares[0]), len(start.squares), len(goal.squares[0]), len(goal.squares))
+	}
+	for i := range start.squares {
+		if len(start.squares[i]) != len(goal.squares[i]) {
+			return fmt.Errorf("%w: row %d is %d wide in start and %d wide in goal", ErrSizeMismatch, i, len(start.squares[i]), len(goal.squares[i]))
+		}
+		for j := range start.squares[i] {
+			if start.squares[i][j].color == Wildcard {
+				return fmt.Errorf("%w: (%d, %d)", ErrWildcardInStart, j, i)
+			}
+			if goal.squares[i][j].color == Wildcard {
+				continue
+			}
+			start_wall := start.squares[i][j].color == Black
+			goal_wall := goal.squares[i][j].color == Black
+			if start_wall != goal_wall {
+				return fmt.Errorf("%w: at (%d, %d) start is %s, goal is %s", ErrWallMismatch, j, i, start.squares[i][j].color, goal.squares[i][j].color)
+			}
+		}
+	}
+	for _, member := range start.team() {
+		if member.face == "" {
+			return fmt.Errorf("%w: direction", ErrWildcardInStart)
+		}
+	}
+	if err := validate_bit(start); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	if !world_has_bit(goal.squares) {
+		return nil
+	}
+	if err := validate_bit(goal); err != nil {
+		return fmt.Errorf("goal: %w", err)
+	}
+	if len(start.team()) != len(goal.team()) {
+		return fmt.Errorf("%w: start has %d, goal has %d", ErrBitCount, len(start.team()), len(goal.team()))
+	}
+	if start.bits != nil && goal.bits != nil {
+		for _, member := range goal.bits {
+			if x, _ := start.BitPositionOf(member.id); x < 0 {
+				return fmt.Errorf("%w: goal has bit %s that start doesn't have", ErrBitCount, member.id)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+   Helper function that checks that a world has the right number of bits and that none of them is on a black square.
+   A world has exactly one bit unless it lists several with bit lines.
+*/
+func validate_bit(world *World) error {
+	var want int = len(world.bits)
+	if want == 0 {
+		want = 1
+	}
+	var count int = 0
+	for i, row := range world.squares {
+		for j, square := range row {
+			if !square.has_bit {
+				continue
+			}
+			count++
+			if count > want {
+				return fmt.Errorf("%w: another bit at (%d, %d)", ErrBitCount, j, i)
+			}
+			if square.color == Black {
+				return fmt.Errorf("%w: (%d, %d)", ErrBitOnWall, j, i)
+			}
+		}
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: no bit found", ErrBitCount)
+	}
+	if count != want {
+		return fmt.Errorf("%w: found %d, want %d", ErrBitCount, count, want)
+	}
+	return nil
+}
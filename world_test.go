@@ -0,0 +1,124 @@
+package bit
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWorldDigitRow(t *testing.T) {
+	_, err := ParseWorld(strings.NewReader("r\n1\n0 0\n"))
+	var parse_err *ParseError
+	if !errors.As(err, &parse_err) || !errors.Is(err, ErrInvalidCharacter) {
+		t.Fatalf("got %v, want an invalid character", err)
+	}
+	if parse_err.Line != 2 || parse_err.Column != 1 {
+		t.Errorf("got line %d column %d, want line 2 column 1", parse_err.Line, parse_err.Column)
+	}
+}
+
+func TestParseWorldCoordinates(t *testing.T) {
+	world, err := ParseWorld(strings.NewReader("r\n--\n10\n1 0\n"))
+	if err == nil {
+		t.Fatalf("got %v, want an error for the row '10'", world)
+	}
+	world, err = ParseWorld(strings.NewReader("r\n--\n--\n1 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x, y := world.BitPosition(); x != 1 || y != 1 {
+		t.Errorf("got (%d, %d), want (1, 1)", x, y)
+	}
+}
+
+func TestSaveWorldRoundTrip(t *testing.T) {
+	worlds := []string{
+		"u\n--------\nrbg-----\nxxx-----\n0 0\n",
+		"?\n-?r\nx-b\n? ?\n",
+		"r\n-----\n-----\nbit: alice 0 0 r\nbit: bob 0 1 u\n",
+		"l\n---\n-g-\n2 1\nobjects: 1 0 3\nobjects: 0 1 1\n",
+	}
+	for _, text := range worlds {
+		world, err := ParseWorld(strings.NewReader(text))
+		if err != nil {
+			t.Fatalf("%q: %v", text, err)
+		}
+		var saved strings.Builder
+		if err := SaveWorld(&saved, world); err != nil {
+			t.Fatal(err)
+		}
+		again, err := ParseWorld(strings.NewReader(saved.String()))
+		if err != nil {
+			t.Fatalf("%q: %v", saved.String(), err)
+		}
+		if !reflect.DeepEqual(world, again) {
+			t.Errorf("%q was saved as %q which parses to a different world", text, saved.String())
+		}
+	}
+}
+
+func TestParseWorldWildcardColumn(t *testing.T) {
+	world, err := ParseWorld(strings.NewReader("r\n-\n?\n0 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if world.Height() != 2 || world.At(0, 1) != Wildcard {
+		t.Errorf("got a %dx%d world, want the row '?' to be a wildcard square", world.Width(), world.Height())
+	}
+	world, err = ParseWorld(strings.NewReader("?\n-\n?\n? ?\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x, y := world.BitPosition(); x != -1 || y != -1 {
+		t.Errorf("got (%d, %d), want bit to be anywhere", x, y)
+	}
+}
+
+func TestLegendPerWorld(t *testing.T) {
+	yellow, err := ParseWorld(strings.NewReader("legend: o tangerine yellow\nr\n-o\n0 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	red, err := ParseWorld(strings.NewReader("legend: t tangerine red\nr\n-t\n0 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if yellow.At(1, 0) != red.At(1, 0) {
+		t.Errorf("got %v and %v, want the same color", yellow.At(1, 0), red.At(1, 0))
+	}
+	var saved strings.Builder
+	if err := SaveWorld(&saved, red); err != nil {
+		t.Fatal(err)
+	}
+	if want := "legend: t tangerine red\nr\n-t\n0 0\n"; saved.String() != want {
+		t.Errorf("got %q, want %q", saved.String(), want)
+	}
+}
+
+func TestParseWorldSignedCoordinates(t *testing.T) {
+	for _, text := range []string{"r\n--\n-0 0\n", "r\n--\n0 +1\n", "r\n--\n-1 0\n"} {
+		_, err := ParseWorld(strings.NewReader(text))
+		var parse_err *ParseError
+		if !errors.As(err, &parse_err) || !errors.Is(err, ErrInvalidCoordinates) {
+			t.Errorf("%q: got %v, want invalid coordinates", text, err)
+			continue
+		}
+		if want := strings.IndexAny(text[5:], "-+") + 1; parse_err.Line != 3 || parse_err.Column != want {
+			t.Errorf("%q: got line %d column %d, want line 3 column %d", text, parse_err.Line, parse_err.Column, want)
+		}
+	}
+}
+
+func TestParseWorldInvalidObjects(t *testing.T) {
+	for _, text := range []string{
+		"r\n-x\n0 0\nobjects: 1 0 1\n",
+		"r\n--\n0 0\nobjects: 1 0 1\nobjects: 1 0 2\n",
+		"r\n--\n0 0\nobjects: 2 0 1\n",
+		"r\n--\n0 0\nobjects: 1 0 0\n",
+	} {
+		if _, err := ParseWorld(strings.NewReader(text)); !errors.Is(err, ErrInvalidObjects) {
+			t.Errorf("%q: got %v, want ErrInvalidObjects", text, err)
+		}
+	}
+}